@@ -4,10 +4,12 @@ go 1.24.4
 
 require (
 	github.com/bwmarrin/discordgo v0.29.0
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0
 	github.com/go-ozzo/ozzo-validation/v4 v4.3.0
 	github.com/joho/godotenv v1.5.1
 	github.com/onrik/gorm-logrus v0.5.0
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b
 	gorm.io/driver/mysql v1.6.0
 	gorm.io/driver/sqlite v1.6.0
 	gorm.io/gorm v1.30.2
@@ -20,7 +22,6 @@ require (
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/mattn/go-sqlite3 v1.14.22 // indirect
-	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b // indirect
 	golang.org/x/sys v0.2.0 // indirect
 	golang.org/x/text v0.20.0 // indirect
 )
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"os"
+	"strconv"
 	"sync"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -22,12 +23,31 @@ type Config struct {
 	STORY_API_KEY            string
 	STORY_API_BASE_URL       string
 	STORY_BUTTON_TIMEOUT_SEC int
+	CUSTOM_ID_SECRET         string
+	// passive IP detection in chat, needs the privileged message content intent
+	AUTO_PREVIEW_ENABLED      bool
+	AUTO_PREVIEW_COOLDOWN_SEC int
+	AUTO_PREVIEW_DEDUP_SEC    int
+	// only list assets that passed moderation; lookups by id are not filtered
+	STORY_MODERATED_ONLY bool
+	// optional Aeneid testnet endpoint; the key defaults to STORY_API_KEY
+	STORY_AENEID_API_BASE_URL string
+	STORY_AENEID_API_KEY      string
+	// mainnet block explorer flavor and explorer/IP portal roots
+	STORY_EXPLORER_KIND string
+	STORY_EXPLORER_URL  string
+	STORY_IP_PORTAL_URL string
 }
 
 func GetEnvConfig() *Config {
 	once.Do(func() {
 		config = &Config{
-			STORY_BUTTON_TIMEOUT_SEC: 300,
+			STORY_BUTTON_TIMEOUT_SEC:  300,
+			AUTO_PREVIEW_COOLDOWN_SEC: 30,
+			AUTO_PREVIEW_DEDUP_SEC:    600,
+			STORY_EXPLORER_KIND:       "storyscan",
+			STORY_EXPLORER_URL:        "https://www.storyscan.io",
+			STORY_IP_PORTAL_URL:       "https://explorer.story.foundation",
 		}
 
 		switch os.Getenv("LOG_LEVEL") {
@@ -55,6 +75,26 @@ func GetEnvConfig() *Config {
 		config.BOT_TOKEN = os.Getenv("BOT_TOKEN")
 		config.STORY_API_KEY = os.Getenv("STORY_API_KEY")
 		config.STORY_API_BASE_URL = os.Getenv("STORY_API_BASE_URL")
+		config.STORY_AENEID_API_BASE_URL = os.Getenv("STORY_AENEID_API_BASE_URL")
+		config.STORY_AENEID_API_KEY = os.Getenv("STORY_AENEID_API_KEY")
+		config.CUSTOM_ID_SECRET = os.Getenv("CUSTOM_ID_SECRET")
+		if v := os.Getenv("STORY_EXPLORER_KIND"); v != "" {
+			config.STORY_EXPLORER_KIND = v
+		}
+		if v := os.Getenv("STORY_EXPLORER_URL"); v != "" {
+			config.STORY_EXPLORER_URL = v
+		}
+		if v := os.Getenv("STORY_IP_PORTAL_URL"); v != "" {
+			config.STORY_IP_PORTAL_URL = v
+		}
+		config.AUTO_PREVIEW_ENABLED, _ = strconv.ParseBool(os.Getenv("AUTO_PREVIEW_ENABLED"))
+		config.STORY_MODERATED_ONLY, _ = strconv.ParseBool(os.Getenv("STORY_MODERATED_ONLY"))
+		if v, err := strconv.Atoi(os.Getenv("AUTO_PREVIEW_COOLDOWN_SEC")); err == nil {
+			config.AUTO_PREVIEW_COOLDOWN_SEC = v
+		}
+		if v, err := strconv.Atoi(os.Getenv("AUTO_PREVIEW_DEDUP_SEC")); err == nil {
+			config.AUTO_PREVIEW_DEDUP_SEC = v
+		}
 
 		if err := validation.ValidateStruct(config,
 			validation.Field(&config.LOCALE, validation.Required, validation.In("en", "ru")),
@@ -65,6 +105,13 @@ func GetEnvConfig() *Config {
 			validation.Field(&config.BOT_TOKEN, validation.Required, validation.Length(1, 256)),
 			validation.Field(&config.STORY_API_KEY, validation.Required, validation.Length(1, 256)),
 			validation.Field(&config.STORY_API_BASE_URL, validation.Required, validation.Length(1, 256)),
+			validation.Field(&config.STORY_AENEID_API_BASE_URL, validation.Length(0, 256)),
+			validation.Field(&config.STORY_AENEID_API_KEY, validation.Length(0, 256)),
+			validation.Field(&config.CUSTOM_ID_SECRET, validation.Length(0, 256)),
+			validation.Field(&config.STORY_EXPLORER_URL, validation.Length(0, 256)),
+			validation.Field(&config.STORY_IP_PORTAL_URL, validation.Length(0, 256)),
+			validation.Field(&config.AUTO_PREVIEW_COOLDOWN_SEC, validation.Min(0), validation.Max(3600)),
+			validation.Field(&config.AUTO_PREVIEW_DEDUP_SEC, validation.Min(0), validation.Max(86400)),
 		); err != nil {
 			logrus.Fatalf("Can't parse .env: %v", err)
 		}
@@ -0,0 +1,234 @@
+// Package customid encodes component custom_ids as compact signed payloads.
+//
+// Layout (version 1), base64url without padding:
+//
+//	version | kind | action | flags | target | [owner u64] | [expiry u32] | [arg uvarint] | [network u8] | mac[8]
+//
+// target is 20 raw bytes when flagAddress is set, else a length-prefixed
+// string. mac is a truncated HMAC-SHA256 over everything before it.
+package customid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	version = 1
+	macSize = 8
+	// MaxLen is Discord's custom_id limit.
+	MaxLen = 100
+	// maxTarget keeps non-address targets well inside MaxLen.
+	maxTarget = 40
+)
+
+const (
+	flagAddress byte = 1 << iota
+	flagOwner
+	flagExpiry
+	flagArg
+	flagNetwork
+)
+
+type Kind byte
+
+const (
+	KindLicense Kind = iota + 1
+	KindCollection
+	KindResult
+	KindCollectionList
+	KindTrending
+)
+
+type Action byte
+
+const (
+	ActionTerms Action = iota + 1
+	ActionInfringement
+	ActionModeration
+	ActionMint
+	ActionCollection
+	ActionRefresh
+	ActionShow
+	ActionDisputes
+	ActionView
+	ActionShare
+	ActionExport
+)
+
+var (
+	ErrMalformed = errors.New("customid: malformed payload")
+	ErrVersion   = errors.New("customid: unsupported version")
+	ErrSignature = errors.New("customid: bad signature")
+	ErrExpired   = errors.New("customid: expired")
+	ErrTooLong   = errors.New("customid: payload exceeds 100 chars")
+)
+
+// Payload is the decoded content of a custom_id.
+type Payload struct {
+	Kind    Kind
+	Action  Action
+	Target  string    // 0x address or short opaque string
+	Owner   string    // Discord user id allowed to use the component, empty for anyone
+	Expires time.Time // zero for no expiry
+	Arg     uint32    // optional action argument, e.g. a page number
+	Network uint8     // index of the network the target lives on, 0 for the default
+}
+
+// Codec signs and verifies payloads with a shared secret.
+type Codec struct {
+	secret []byte
+	now    func() time.Time
+}
+
+func New(secret []byte) *Codec {
+	return &Codec{secret: secret, now: time.Now}
+}
+
+// Encode packs and signs p.
+func (c *Codec) Encode(p Payload) (string, error) {
+	buf := []byte{version, byte(p.Kind), byte(p.Action), 0}
+	var flags byte
+
+	if addr, ok := parseAddress(p.Target); ok {
+		flags |= flagAddress
+		buf = append(buf, addr...)
+	} else {
+		if len(p.Target) > maxTarget {
+			return "", ErrTooLong
+		}
+		buf = append(buf, byte(len(p.Target)))
+		buf = append(buf, p.Target...)
+	}
+	if p.Owner != "" {
+		id, err := strconv.ParseUint(p.Owner, 10, 64)
+		if err != nil {
+			return "", ErrMalformed
+		}
+		flags |= flagOwner
+		buf = binary.BigEndian.AppendUint64(buf, id)
+	}
+	if !p.Expires.IsZero() {
+		flags |= flagExpiry
+		buf = binary.BigEndian.AppendUint32(buf, uint32(p.Expires.Unix()))
+	}
+	if p.Arg != 0 {
+		flags |= flagArg
+		buf = binary.AppendUvarint(buf, uint64(p.Arg))
+	}
+	if p.Network != 0 {
+		flags |= flagNetwork
+		buf = append(buf, p.Network)
+	}
+	buf[3] = flags
+	buf = append(buf, c.mac(buf)...)
+
+	out := base64.RawURLEncoding.EncodeToString(buf)
+	if len(out) > MaxLen {
+		return "", ErrTooLong
+	}
+	return out, nil
+}
+
+// Decode verifies and unpacks a custom_id. Expired payloads return ErrExpired.
+func (c *Codec) Decode(s string) (Payload, error) {
+	var p Payload
+	if len(s) > MaxLen {
+		return p, ErrTooLong
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(buf) < 5+macSize {
+		return p, ErrMalformed
+	}
+	if buf[0] != version {
+		return p, ErrVersion
+	}
+	body, sig := buf[:len(buf)-macSize], buf[len(buf)-macSize:]
+	if !hmac.Equal(sig, c.mac(body)) {
+		return p, ErrSignature
+	}
+
+	p.Kind = Kind(body[1])
+	p.Action = Action(body[2])
+	flags := body[3]
+	if flags&^(flagAddress|flagOwner|flagExpiry|flagArg|flagNetwork) != 0 {
+		return p, ErrMalformed
+	}
+	rest := body[4:]
+
+	if flags&flagAddress != 0 {
+		if len(rest) < 20 {
+			return p, ErrMalformed
+		}
+		p.Target = "0x" + hex.EncodeToString(rest[:20])
+		rest = rest[20:]
+	} else {
+		n := int(rest[0])
+		if len(rest) < 1+n {
+			return p, ErrMalformed
+		}
+		p.Target = string(rest[1 : 1+n])
+		rest = rest[1+n:]
+	}
+	if flags&flagOwner != 0 {
+		if len(rest) < 8 {
+			return p, ErrMalformed
+		}
+		p.Owner = strconv.FormatUint(binary.BigEndian.Uint64(rest), 10)
+		rest = rest[8:]
+	}
+	if flags&flagExpiry != 0 {
+		if len(rest) < 4 {
+			return p, ErrMalformed
+		}
+		p.Expires = time.Unix(int64(binary.BigEndian.Uint32(rest)), 0).UTC()
+		rest = rest[4:]
+	}
+	if flags&flagArg != 0 {
+		v, n := binary.Uvarint(rest)
+		if n <= 0 || v > 1<<32-1 {
+			return p, ErrMalformed
+		}
+		p.Arg = uint32(v)
+		rest = rest[n:]
+	}
+	if flags&flagNetwork != 0 {
+		if len(rest) < 1 || rest[0] == 0 {
+			return p, ErrMalformed
+		}
+		p.Network = rest[0]
+		rest = rest[1:]
+	}
+	if len(rest) != 0 {
+		return p, ErrMalformed
+	}
+	if !p.Expires.IsZero() && c.now().After(p.Expires) {
+		return p, ErrExpired
+	}
+	return p, nil
+}
+
+func (c *Codec) mac(data []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(data)
+	return h.Sum(nil)[:macSize]
+}
+
+// parseAddress returns the raw bytes of a 0x-prefixed 20-byte hex address.
+func parseAddress(s string) ([]byte, bool) {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"github.com/goldsheva/discord-story-bot/internal/configs"
+	"github.com/goldsheva/discord-story-bot/internal/models"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -22,12 +23,27 @@ func InitDB() *gorm.DB {
 		DB = InitSQLite()
 	}
 
+	if err := DB.AutoMigrate(
+		&models.GuildSettings{},
+		&models.LookupHistory{},
+		&models.PreviewChannel{},
+		&models.WalletLink{},
+		&models.MessageExpiry{},
+		&models.LicenseSnapshot{},
+		&models.DescendantSnapshot{},
+	); err != nil {
+		logrus.Fatal("Can't migrate database: ", err)
+	}
+
 	logrus.WithFields(logrus.Fields{"gopher": "main", "driver": config.DB_DRIVER}).Info("Database connection established")
 
 	return DB
 }
 
 func GetDB() *gorm.DB {
+	if DB == nil {
+		return InitDB()
+	}
 	sqlDB, _ := DB.DB()
 	if err := sqlDB.Ping(); err != nil {
 		DB = InitDB()
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"time"
+
+	"github.com/goldsheva/discord-story-bot/internal/models"
+)
+
+// SaveDescendantSnapshots stores the counts of IPs on network whose latest
+// snapshot is older than minAge, or that have none yet.
+func SaveDescendantSnapshots(network string, counts map[string]int, minAge time.Duration) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	db := GetDB()
+	now := time.Now().UTC()
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+
+	var recent []string
+	if err := db.Model(&models.DescendantSnapshot{}).
+		Where("network = ? AND ip_id IN ? AND observed_at > ?", network, ids, now.Add(-minAge)).
+		Distinct().Pluck("ip_id", &recent).Error; err != nil {
+		return err
+	}
+	skip := make(map[string]struct{}, len(recent))
+	for _, id := range recent {
+		skip[id] = struct{}{}
+	}
+
+	var rows []models.DescendantSnapshot
+	for id, n := range counts {
+		if _, ok := skip[id]; !ok {
+			rows = append(rows, models.DescendantSnapshot{Network: network, IPID: id, Count: n, ObservedAt: now})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Create(&rows).Error
+}
+
+// DescendantCountsAt returns, per IP on network, the count of its latest
+// snapshot taken at or before t. IPs without such a snapshot are absent.
+func DescendantCountsAt(network string, ipIDs []string, t time.Time) (map[string]int, error) {
+	out := map[string]int{}
+	if len(ipIDs) == 0 {
+		return out, nil
+	}
+	var rows []models.DescendantSnapshot
+	err := GetDB().Where("network = ? AND ip_id IN ? AND observed_at <= ?", network, ipIDs, t.UTC()).Order("observed_at asc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.IPID] = r.Count
+	}
+	return out, nil
+}
+
+// PruneDescendantSnapshots deletes snapshots taken before t.
+func PruneDescendantSnapshots(t time.Time) error {
+	return GetDB().Where("observed_at < ?", t.UTC()).Delete(&models.DescendantSnapshot{}).Error
+}
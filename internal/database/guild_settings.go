@@ -0,0 +1,38 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+// GetGuildSettings returns stored settings for a guild or nil if none were saved.
+func GetGuildSettings(guildID string) (*models.GuildSettings, error) {
+	var gs models.GuildSettings
+	err := GetDB().Where("guild_id = ?", guildID).First(&gs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &gs, nil
+}
+
+// SaveGuildSettings inserts or updates the settings row of a guild.
+func SaveGuildSettings(gs *models.GuildSettings) error {
+	return GetDB().Save(gs).Error
+}
+
+// ListFeedGuilds returns the settings of guilds that set a feed channel.
+func ListFeedGuilds() ([]models.GuildSettings, error) {
+	var out []models.GuildSettings
+	err := GetDB().Where("feed_channel_id <> ?", "").Find(&out).Error
+	return out, err
+}
+
+// DeleteGuildSettings removes all overrides of a guild.
+func DeleteGuildSettings(guildID string) error {
+	return GetDB().Where("guild_id = ?", guildID).Delete(&models.GuildSettings{}).Error
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"time"
+
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+// SaveLicenseSnapshots stores each row whose hash differs from the latest
+// snapshot of the same network, IP and license terms, and bumps LastSeenAt of
+// that snapshot otherwise. It reports how many new snapshots were stored.
+func SaveLicenseSnapshots(rows []models.LicenseSnapshot) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UTC()
+	stored := 0
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		ids := map[string]map[string]struct{}{}
+		for _, r := range rows {
+			if ids[r.Network] == nil {
+				ids[r.Network] = map[string]struct{}{}
+			}
+			ids[r.Network][r.IPID] = struct{}{}
+		}
+		latest := map[string]models.LicenseSnapshot{}
+		for network, set := range ids {
+			list := make([]string, 0, len(set))
+			for id := range set {
+				list = append(list, id)
+			}
+			var existing []models.LicenseSnapshot
+			if err := tx.Select("id", "network", "ip_id", "license_terms_id", "hash").
+				Where("network = ? AND ip_id IN ?", network, list).
+				Order("observed_at asc, id asc").Find(&existing).Error; err != nil {
+				return err
+			}
+			for _, e := range existing {
+				latest[licenseSnapshotKey(&e)] = e
+			}
+		}
+
+		var fresh []models.LicenseSnapshot
+		seen := map[uint]struct{}{}
+		for _, r := range rows {
+			key := licenseSnapshotKey(&r)
+			if last, ok := latest[key]; ok && last.Hash == r.Hash {
+				// ID is zero for a row stored earlier in this batch
+				if last.ID != 0 {
+					seen[last.ID] = struct{}{}
+				}
+				continue
+			}
+			r.ID, r.ObservedAt, r.LastSeenAt = 0, now, now
+			fresh = append(fresh, r)
+			latest[key] = r
+		}
+		if len(seen) > 0 {
+			list := make([]uint, 0, len(seen))
+			for id := range seen {
+				list = append(list, id)
+			}
+			if err := tx.Model(&models.LicenseSnapshot{}).Where("id IN ?", list).Update("last_seen_at", now).Error; err != nil {
+				return err
+			}
+		}
+		if len(fresh) > 0 {
+			if err := tx.Create(&fresh).Error; err != nil {
+				return err
+			}
+		}
+		stored = len(fresh)
+		return nil
+	})
+	return stored, err
+}
+
+func licenseSnapshotKey(s *models.LicenseSnapshot) string {
+	return s.Network + "/" + s.IPID + "/" + s.LicenseTermsID
+}
+
+// LicenseSnapshots returns the snapshots of all licenses of the IP on network,
+// newest first.
+func LicenseSnapshots(network, ipID string, limit int) ([]models.LicenseSnapshot, error) {
+	var out []models.LicenseSnapshot
+	err := GetDB().Where("network = ? AND ip_id = ?", network, ipID).Order("observed_at desc, id desc").Limit(limit).Find(&out).Error
+	return out, err
+}
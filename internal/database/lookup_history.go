@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+// lookupHistoryLimit is how many entries are kept per user, network and kind.
+const lookupHistoryLimit = 25
+
+// RecordLookup upserts a lookup for the user on network and trims old entries.
+// An empty label keeps the previously stored one.
+func RecordLookup(userID, network, kind, value, label string) error {
+	db := GetDB()
+	now := time.Now().UTC()
+
+	var h models.LookupHistory
+	err := db.Where("user_id = ? AND network = ? AND kind = ? AND value = ?", userID, network, kind, value).First(&h).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h = models.LookupHistory{UserID: userID, Network: network, Kind: kind, Value: value, Label: label, LookedUpAt: now}
+		if err := db.Create(&h).Error; err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		h.LookedUpAt = now
+		if label != "" {
+			h.Label = label
+		}
+		if err := db.Save(&h).Error; err != nil {
+			return err
+		}
+	}
+
+	// keep only the most recent entries
+	var stale []uint
+	if err := db.Model(&models.LookupHistory{}).
+		Where("user_id = ? AND network = ? AND kind = ?", userID, network, kind).
+		Order("looked_up_at desc").
+		Offset(lookupHistoryLimit).
+		Pluck("id", &stale).Error; err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		return db.Delete(&models.LookupHistory{}, stale).Error
+	}
+	return nil
+}
+
+// likeEscaper escapes LIKE wildcards for an ESCAPE '!' clause; '!' is used
+// because a backslash needs different quoting in MySQL and SQLite.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// RecentLookups returns the user's most recent lookups on network whose value
+// or label contains query.
+func RecentLookups(userID, network, kind, query string, limit int) ([]models.LookupHistory, error) {
+	q := GetDB().Where("user_id = ? AND network = ? AND kind = ?", userID, network, kind)
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		like := "%" + likeEscaper.Replace(query) + "%"
+		q = q.Where("LOWER(value) LIKE ? ESCAPE '!' OR LOWER(label) LIKE ? ESCAPE '!'", like, like)
+	}
+	var out []models.LookupHistory
+	err := q.Order("looked_up_at desc").Limit(limit).Find(&out).Error
+	return out, err
+}
@@ -0,0 +1,24 @@
+package database
+
+import (
+	"time"
+
+	"github.com/goldsheva/discord-story-bot/internal/models"
+)
+
+// SaveMessageExpiry inserts or replaces the expiry record of a message.
+func SaveMessageExpiry(me *models.MessageExpiry) error {
+	return GetDB().Save(me).Error
+}
+
+// DueMessageExpiries returns up to limit records that expired before now.
+func DueMessageExpiries(now time.Time, limit int) ([]models.MessageExpiry, error) {
+	var out []models.MessageExpiry
+	err := GetDB().Where("expires_at <= ?", now).Order("expires_at").Limit(limit).Find(&out).Error
+	return out, err
+}
+
+// DeleteMessageExpiry removes the expiry record of a message.
+func DeleteMessageExpiry(messageID string) error {
+	return GetDB().Where("message_id = ?", messageID).Delete(&models.MessageExpiry{}).Error
+}
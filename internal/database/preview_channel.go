@@ -0,0 +1,31 @@
+package database
+
+import (
+	"github.com/goldsheva/discord-story-bot/internal/models"
+)
+
+// ListPreviewChannels returns the channel ids with auto-previews enabled.
+// An empty guildID lists channels of all guilds.
+func ListPreviewChannels(guildID string) ([]string, error) {
+	q := GetDB().Model(&models.PreviewChannel{})
+	if guildID != "" {
+		q = q.Where("guild_id = ?", guildID)
+	}
+	var ids []string
+	err := q.Order("created_at").Pluck("channel_id", &ids).Error
+	return ids, err
+}
+
+// SetPreviewChannel enables or disables auto-previews in a channel.
+func SetPreviewChannel(guildID, channelID, userID string, enabled bool) error {
+	db := GetDB()
+	if !enabled {
+		return db.Where("channel_id = ?", channelID).Delete(&models.PreviewChannel{}).Error
+	}
+	return db.Save(&models.PreviewChannel{ChannelID: channelID, GuildID: guildID, EnabledBy: userID}).Error
+}
+
+// DeletePreviewChannels disables auto-previews in every channel of a guild.
+func DeletePreviewChannels(guildID string) error {
+	return GetDB().Where("guild_id = ?", guildID).Delete(&models.PreviewChannel{}).Error
+}
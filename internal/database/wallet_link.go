@@ -0,0 +1,31 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"gorm.io/gorm"
+)
+
+// GetWalletLink returns the wallet link of a user or nil if none exists.
+func GetWalletLink(userID string) (*models.WalletLink, error) {
+	var wl models.WalletLink
+	err := GetDB().Where("user_id = ?", userID).First(&wl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wl, nil
+}
+
+// SaveWalletLink inserts or updates a wallet link.
+func SaveWalletLink(wl *models.WalletLink) error {
+	return GetDB().Save(wl).Error
+}
+
+// DeleteWalletLink removes the wallet link of a user.
+func DeleteWalletLink(userID string) error {
+	return GetDB().Where("user_id = ?", userID).Delete(&models.WalletLink{}).Error
+}
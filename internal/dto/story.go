@@ -29,6 +29,8 @@ type IPAsset struct {
 	Collection      *CollectionInfo       `json:"collection"`
 	TokenContract   string                `json:"tokenContract"`
 	TokenId         string                `json:"tokenId"`
+	ChildrenCount   int                   `json:"childrenCount"`
+	DescendantCount int                   `json:"descendantsCount"`
 }
 
 type NFTMetadata struct {
@@ -52,21 +54,45 @@ type NFTAnimationMetadata struct {
 }
 
 type LicenseTermsWrapper struct {
-	TemplateName        string        `json:"templateName"`
-	TemplateMetadataUri string        `json:"templateMetadataUri"`
-	LicenseTemplateId   string        `json:"licenseTemplateId"`
-	Terms               *LicenseTerms `json:"terms"`
+	TemplateName        string           `json:"templateName"`
+	TemplateMetadataUri string           `json:"templateMetadataUri"`
+	LicenseTemplateId   string           `json:"licenseTemplateId"`
+	LicenseTermsId      string           `json:"licenseTermsId"`
+	Terms               *LicenseTerms    `json:"terms"`
+	LicensingConfig     *LicensingConfig `json:"licensingConfig"`
 }
 
+// LicenseTerms are the Programmable IP License (PIL) terms.
 type LicenseTerms struct {
-	Transferable           bool `json:"transferable"`
-	CommercialUse          bool `json:"commercialUse"`
-	DerivativesAllowed     bool `json:"derivativesAllowed"`
-	DerivativesApproval    bool `json:"derivativesApproval"`
-	CommercialRevShare     int  `json:"commercialRevShare"`
-	AttributionRequired    bool `json:"attributionRequired"`
-	DerivativesAttribution bool `json:"derivativesAttribution"`
-	CommercialAttribution  bool `json:"commercialAttribution"`
+	Transferable              bool   `json:"transferable"`
+	CommercialUse             bool   `json:"commercialUse"`
+	DerivativesAllowed        bool   `json:"derivativesAllowed"`
+	DerivativesApproval       bool   `json:"derivativesApproval"`
+	CommercialRevShare        int    `json:"commercialRevShare"`
+	AttributionRequired       bool   `json:"attributionRequired"`
+	DerivativesAttribution    bool   `json:"derivativesAttribution"`
+	CommercialAttribution     bool   `json:"commercialAttribution"`
+	DerivativesReciprocal     bool   `json:"derivativesReciprocal"`
+	CommercialRevCeiling      string `json:"commercialRevCeiling"`
+	DerivativeRevCeiling      string `json:"derivativeRevCeiling"`
+	CommercializerChecker     string `json:"commercializerChecker"`
+	CommercializerCheckerData string `json:"commercializerCheckerData"`
+	Currency                  string `json:"currency"`
+	DefaultMintingFee         string `json:"defaultMintingFee"`
+	Expiration                string `json:"expiration"`
+	RoyaltyPolicy             string `json:"royaltyPolicy"`
+	URI                       string `json:"uri"`
+}
+
+type LicensingConfig struct {
+	IsSet                         bool         `json:"isSet"`
+	Disabled                      bool         `json:"disabled"`
+	MintingFee                    *json.Number `json:"mintingFee"`
+	CommercialRevShare            int          `json:"commercialRevShare"`
+	LicensingHook                 string       `json:"licensingHook"`
+	HookData                      string       `json:"hookData"`
+	ExpectMinimumGroupRewardShare int          `json:"expectMinimumGroupRewardShare"`
+	ExpectGroupRewardPool         string       `json:"expectGroupRewardPool"`
 }
 
 type InfringementStatus struct {
@@ -121,13 +147,34 @@ type CollectionsResponse struct {
 }
 
 type CollectionItem struct {
+	CollectionAddress     string             `json:"collectionAddress"`
 	CollectionMetadata    CollectionMetadata `json:"collectionMetadata"`
+	AssetCount            int                `json:"assetCount"`
+	LicensesCount         int                `json:"licensesCount"`
 	CancelledDisputeCount int                `json:"cancelledDisputeCount"`
 	ResolvedDisputeCount  int                `json:"resolvedDisputeCount"`
 	RaisedDisputeCount    int                `json:"raisedDisputeCount"`
 	JudgedDisputeCount    int                `json:"judgedDisputeCount"`
 }
 
+// Disputes response
+type DisputesResponse struct {
+	Data []Dispute `json:"data"`
+}
+
+type Dispute struct {
+	ID                string `json:"id"`
+	TargetIpId        string `json:"targetIpId"`
+	TargetTag         string `json:"targetTag"`
+	CurrentTag        string `json:"currentTag"`
+	Initiator         string `json:"initiator"`
+	Status            string `json:"status"`
+	DisputeTimestamp  string `json:"disputeTimestamp"`
+	TransactionHash   string `json:"transactionHash"`
+	ArbitrationPolicy string `json:"arbitrationPolicy"`
+	UmaLink           string `json:"umaLink"`
+}
+
 type CollectionMetadata struct {
 	Address         string       `json:"address"`
 	Name            string       `json:"name"`
@@ -143,3 +190,18 @@ type CollectionMetadata struct {
 	TwitterUsername string       `json:"twitterUsername"`
 	DiscordUrl      string       `json:"discordUrl"`
 }
+
+// Search response
+type SearchResponse struct {
+	Data  []IPSearchResult `json:"data"`
+	Total int              `json:"total"`
+}
+
+type IPSearchResult struct {
+	IpId        string  `json:"ipId"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	MediaType   string  `json:"mediaType"`
+	Score       float64 `json:"score"`
+	Similarity  float64 `json:"similarity"`
+}
@@ -0,0 +1,104 @@
+// Package explorer builds links to block explorers and the IP portal.
+// Explorer flavors register a Factory under a kind name; networks pick one
+// by configuration.
+package explorer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Links builds URLs for one explorer deployment. A method returns "" when
+// the deployment has no page of that kind.
+type Links interface {
+	Tx(hash string) string
+	Address(address string) string
+	Token(contract, tokenID string) string
+	IP(ipID string) string
+}
+
+// Factory creates Links for an explorer at baseURL and an IP portal at
+// portalURL. Either may be empty.
+type Factory func(baseURL, portalURL string) Links
+
+const (
+	KindBlockscout = "blockscout"
+	KindEtherscan  = "etherscan"
+	// KindStoryscan is Blockscout under the name Story uses for it
+	KindStoryscan = "storyscan"
+)
+
+var (
+	factories   = map[string]Factory{}
+	factoriesMu sync.RWMutex
+)
+
+func init() {
+	Register(KindBlockscout, newBlockscout)
+	Register(KindStoryscan, newBlockscout)
+	Register(KindEtherscan, newEtherscan)
+}
+
+// Register makes an explorer kind available to New, replacing any factory
+// registered under the same kind.
+func Register(kind string, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[strings.ToLower(kind)] = f
+}
+
+// New returns the Links of kind for the given URLs.
+func New(kind, baseURL, portalURL string) (Links, error) {
+	factoriesMu.RLock()
+	f, ok := factories[strings.ToLower(kind)]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("explorer: unknown kind %q", kind)
+	}
+	return f(strings.TrimRight(baseURL, "/"), strings.TrimRight(portalURL, "/")), nil
+}
+
+// paths is a Links built from path templates below the explorer root.
+type paths struct {
+	base, portal string
+	tx, address  string
+	token        string // gets contract and token id
+}
+
+func (p paths) Tx(hash string) string {
+	if p.base == "" || hash == "" {
+		return ""
+	}
+	return p.base + fmt.Sprintf(p.tx, url.PathEscape(hash))
+}
+
+func (p paths) Address(address string) string {
+	if p.base == "" || address == "" {
+		return ""
+	}
+	return p.base + fmt.Sprintf(p.address, url.PathEscape(address))
+}
+
+func (p paths) Token(contract, tokenID string) string {
+	if p.base == "" || contract == "" || tokenID == "" {
+		return ""
+	}
+	return p.base + fmt.Sprintf(p.token, url.PathEscape(contract), url.PathEscape(tokenID))
+}
+
+func (p paths) IP(ipID string) string {
+	if p.portal == "" || ipID == "" {
+		return ""
+	}
+	return p.portal + "/ipa/" + url.PathEscape(ipID)
+}
+
+func newBlockscout(baseURL, portalURL string) Links {
+	return paths{base: baseURL, portal: portalURL, tx: "/tx/%s", address: "/address/%s", token: "/token/%s/instance/%s"}
+}
+
+func newEtherscan(baseURL, portalURL string) Links {
+	return paths{base: baseURL, portal: portalURL, tx: "/tx/%s", address: "/address/%s", token: "/nft/%s/%s"}
+}
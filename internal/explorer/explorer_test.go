@@ -0,0 +1,105 @@
+package explorer
+
+import "testing"
+
+const (
+	tx       = "0x5f3c1a2b4d6e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718"
+	address  = "0x1234567890AbcdEF1234567890aBcdef12345678"
+	contract = "0xabcdef1234567890abcdef1234567890abcdef12"
+	ipID     = "0x9876543210fedcba9876543210fedcba98765432"
+)
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		kind, base, portal string
+		tx, address, token string
+		ip                 string
+	}{
+		{
+			kind: KindStoryscan, base: "https://www.storyscan.io", portal: "https://explorer.story.foundation",
+			tx:      "https://www.storyscan.io/tx/" + tx,
+			address: "https://www.storyscan.io/address/" + address,
+			token:   "https://www.storyscan.io/token/" + contract + "/instance/42",
+			ip:      "https://explorer.story.foundation/ipa/" + ipID,
+		},
+		{
+			// trailing slashes are trimmed and kinds are case-insensitive
+			kind: "Blockscout", base: "https://aeneid.storyscan.io/", portal: "https://aeneid.explorer.story.foundation/",
+			tx:      "https://aeneid.storyscan.io/tx/" + tx,
+			address: "https://aeneid.storyscan.io/address/" + address,
+			token:   "https://aeneid.storyscan.io/token/" + contract + "/instance/42",
+			ip:      "https://aeneid.explorer.story.foundation/ipa/" + ipID,
+		},
+		{
+			kind: KindEtherscan, base: "https://etherscan.example", portal: "",
+			tx:      "https://etherscan.example/tx/" + tx,
+			address: "https://etherscan.example/address/" + address,
+			token:   "https://etherscan.example/nft/" + contract + "/42",
+			ip:      "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			l, err := New(tt.kind, tt.base, tt.portal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range []struct{ name, got, want string }{
+				{"Tx", l.Tx(tx), tt.tx},
+				{"Address", l.Address(address), tt.address},
+				{"Token", l.Token(contract, "42"), tt.token},
+				{"IP", l.IP(ipID), tt.ip},
+			} {
+				if c.got != c.want {
+					t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyInputs(t *testing.T) {
+	l, err := New(KindStoryscan, "https://www.storyscan.io", "https://explorer.story.foundation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]string{
+		"Tx":            l.Tx(""),
+		"Address":       l.Address(""),
+		"Token no id":   l.Token(contract, ""),
+		"Token no addr": l.Token("", "42"),
+		"IP":            l.IP(""),
+	} {
+		if got != "" {
+			t.Errorf("%s = %q, want empty", name, got)
+		}
+	}
+
+	none, _ := New(KindStoryscan, "", "")
+	if got := none.Tx(tx) + none.Address(address) + none.Token(contract, "1") + none.IP(ipID); got != "" {
+		t.Errorf("links without roots = %q, want empty", got)
+	}
+}
+
+func TestEscapesPathSegments(t *testing.T) {
+	l, _ := New(KindStoryscan, "https://www.storyscan.io", "https://explorer.story.foundation")
+	if got, want := l.Address("a/b?c"), "https://www.storyscan.io/address/a%2Fb%3Fc"; got != want {
+		t.Errorf("Address = %q, want %q", got, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if _, err := New("nope", "https://x", ""); err == nil {
+		t.Fatal("expected an error for an unknown kind")
+	}
+	Register("test-custom", func(base, portal string) Links {
+		return paths{base: base, portal: portal, tx: "/transaction/%s", address: "/account/%s", token: "/nft/%s/%s"}
+	})
+	l, err := New("TEST-CUSTOM", "https://custom.example", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := l.Tx("0x1"), "https://custom.example/transaction/0x1"; got != want {
+		t.Errorf("Tx = %q, want %q", got, want)
+	}
+}
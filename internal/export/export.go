@@ -0,0 +1,129 @@
+// Package export renders fetched API data as archivable JSON, CSV or Markdown
+// documents, each carrying when and from where the data was fetched.
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "md"
+)
+
+// Formats lists the supported formats in display order.
+var Formats = []Format{FormatJSON, FormatCSV, FormatMarkdown}
+
+// Header describes the provenance of an export.
+type Header struct {
+	Title     string    `json:"title"`
+	Subject   string    `json:"subject"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	APIBase   string    `json:"apiBaseUrl"`
+}
+
+// Render encodes data in format f and returns the document with a file name.
+// FetchedAt is always written in UTC.
+func Render(f Format, h Header, data interface{}) ([]byte, string, error) {
+	h.FetchedAt = h.FetchedAt.UTC()
+	name := fmt.Sprintf("%s-%s.%s", strings.ToLower(strings.ReplaceAll(h.Title, " ", "_")), h.FetchedAt.Format("20060102T150405Z"), f)
+	switch f {
+	case FormatJSON:
+		b, err := json.MarshalIndent(struct {
+			Header
+			Data interface{} `json:"data"`
+		}{h, data}, "", "  ")
+		return b, name, err
+	case FormatCSV:
+		rows, err := flatten(data)
+		if err != nil {
+			return nil, "", err
+		}
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		_ = w.Write([]string{"field", "value"})
+		_ = w.Write([]string{"_subject", h.Subject})
+		_ = w.Write([]string{"_fetched_at", h.FetchedAt.UTC().Format(time.RFC3339)})
+		_ = w.Write([]string{"_api_base_url", h.APIBase})
+		for _, r := range rows {
+			_ = w.Write(r[:])
+		}
+		w.Flush()
+		return buf.Bytes(), name, w.Error()
+	case FormatMarkdown:
+		rows, err := flatten(data)
+		if err != nil {
+			return nil, "", err
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "# %s — %s\n\n", h.Title, h.Subject)
+		fmt.Fprintf(&b, "- Fetched at: %s\n", h.FetchedAt.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&b, "- API: %s\n\n", h.APIBase)
+		b.WriteString("| Field | Value |\n|---|---|\n")
+		for _, r := range rows {
+			fmt.Fprintf(&b, "| %s | %s |\n", mdEscape(r[0]), mdEscape(r[1]))
+		}
+		return []byte(b.String()), name, nil
+	}
+	return nil, "", fmt.Errorf("export: unsupported format %q", f)
+}
+
+// flatten turns data into dotted-path/value pairs via its JSON form. Object
+// keys are sorted, array elements keep their order.
+func flatten(data interface{}) ([][2]string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var out [][2]string
+	walk("", v, &out)
+	return out, nil
+}
+
+func walk(prefix string, v interface{}, out *[][2]string) {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walk(join(k), t[k], out)
+		}
+	case []interface{}:
+		for k, c := range t {
+			walk(join(fmt.Sprint(k)), c, out)
+		}
+	case nil:
+		// absent values carry no evidence
+	default:
+		*out = append(*out, [2]string{prefix, fmt.Sprint(t)})
+	}
+}
+
+func mdEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// sample mirrors the shape of an asset export: nested objects, arrays,
+// nulls, numbers and strings that need escaping.
+var sample = map[string]interface{}{
+	"ipId":        "0x1234567890abcdef1234567890abcdef12345678",
+	"title":       "Song | with pipe",
+	"description": "line one\nline two",
+	"licenses": []interface{}{
+		map[string]interface{}{
+			"templateName": "Commercial Remix",
+			"terms": map[string]interface{}{
+				"commercialUse":      true,
+				"commercialRevShare": 10000000,
+				"expiration":         nil,
+			},
+		},
+	},
+	"mintingFee": "1000000000000000000",
+	"children":   []interface{}{},
+}
+
+// history has more than ten array elements, whose order must survive
+// flattening.
+var history = map[string]interface{}{
+	"snapshots": func() []interface{} {
+		out := make([]interface{}, 12)
+		for k := range out {
+			out[k] = map[string]interface{}{"hash": fmt.Sprintf("h%02d", k)}
+		}
+		return out
+	}(),
+}
+
+var sampleHeader = Header{
+	Title:     "License Card",
+	Subject:   "0x1234567890abcdef1234567890abcdef12345678",
+	FetchedAt: time.Date(2025, 3, 14, 15, 9, 26, 0, time.FixedZone("CET", 3600)),
+	APIBase:   "https://api.storyapis.com/api/v4",
+}
+
+func TestRenderGolden(t *testing.T) {
+	cases := []struct {
+		name, title string
+		data        interface{}
+	}{
+		{"license_card", "License Card", sample},
+		{"license_history", "License History", history},
+	}
+	for _, c := range cases {
+		for _, f := range Formats {
+			t.Run(c.name+"/"+string(f), func(t *testing.T) {
+				h := sampleHeader
+				h.Title = c.title
+				got, name, err := Render(f, h, c.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := c.name + "-20250314T140926Z." + string(f); name != want {
+					t.Fatalf("name = %q, want %q", name, want)
+				}
+				golden := filepath.Join("testdata", c.name+"."+string(f)+".golden")
+				if *update {
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != string(want) {
+					t.Fatalf("output differs from %s:\n%s", golden, got)
+				}
+			})
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	rows, err := flatten(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{
+		{"description", "line one\nline two"},
+		{"ipId", "0x1234567890abcdef1234567890abcdef12345678"},
+		{"licenses.0.templateName", "Commercial Remix"},
+		{"licenses.0.terms.commercialRevShare", "10000000"},
+		{"licenses.0.terms.commercialUse", "true"},
+		{"mintingFee", "1000000000000000000"},
+		{"title", "Song | with pipe"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows %v, want %d", len(rows), rows, len(want))
+	}
+	for k := range want {
+		if rows[k] != want[k] {
+			t.Fatalf("row %d = %v, want %v", k, rows[k], want[k])
+		}
+	}
+}
+
+func TestRenderUnsupportedFormat(t *testing.T) {
+	if _, _, err := Render("xml", sampleHeader, sample); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// DescendantSnapshot is the descendant count of an IP asset at one point in
+// time. At most one row per IP, network and snapshot interval is kept, so
+// trends can be computed against older counts.
+type DescendantSnapshot struct {
+	ID         uint   `gorm:"primaryKey"`
+	Network    string `gorm:"size:16;index:idx_descendants_ip_observed"`
+	IPID       string `gorm:"size:64;index:idx_descendants_ip_observed"`
+	Count      int
+	ObservedAt time.Time `gorm:"index:idx_descendants_ip_observed"`
+}
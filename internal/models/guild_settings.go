@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// GuildSettings holds per-guild overrides of the global env configuration.
+// Zero values mean "not set" and fall back to configs.Config.
+type GuildSettings struct {
+	GuildID          string `gorm:"primaryKey;size:32"`
+	Locale           string `gorm:"size:8"`
+	ButtonTimeoutSec int
+	Ephemeral        *bool
+	AlertChannelID   string `gorm:"size:32"`
+	FooterText       string `gorm:"size:256"`
+	EmbedColor       *int
+	ExpiryMode       string `gorm:"size:16"`
+	ModerationPolicy string `gorm:"size:256"` // moderation.Policy in its String form
+	FeedChannelID    string `gorm:"size:32;index"`
+	FeedMediaType    string `gorm:"size:16"` // empty posts all media types
+	Network          string `gorm:"size:16"` // default network name
+	UpdatedBy        string `gorm:"size:32"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
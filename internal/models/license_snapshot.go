@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LicenseSnapshot is a distinct version of one license terms set attached to
+// an IP asset. A new row is stored only when the content hash of that license
+// changes; LastSeenAt tracks how long a version stayed current.
+type LicenseSnapshot struct {
+	ID             uint      `gorm:"primaryKey"`
+	Network        string    `gorm:"size:16;index:idx_snapshot_ip_observed"`
+	IPID           string    `gorm:"size:64;index:idx_snapshot_ip_observed"`
+	LicenseTermsID string    `gorm:"size:80"`
+	Hash           string    `gorm:"size:64"`
+	Fields         string    `gorm:"type:text"` // JSON of the flattened license fields
+	ObservedAt     time.Time `gorm:"index:idx_snapshot_ip_observed"`
+	LastSeenAt     time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+const (
+	LookupKindIP         = "ip"
+	LookupKindCollection = "collection"
+)
+
+// LookupHistory remembers what a user looked up recently on a network, for
+// autocomplete.
+type LookupHistory struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     string    `gorm:"size:32;uniqueIndex:idx_lookup_user_kind_value"`
+	Network    string    `gorm:"size:16;uniqueIndex:idx_lookup_user_kind_value"`
+	Kind       string    `gorm:"size:16;uniqueIndex:idx_lookup_user_kind_value"`
+	Value      string    `gorm:"size:128;uniqueIndex:idx_lookup_user_kind_value"`
+	Label      string    `gorm:"size:256"`
+	LookedUpAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	ExpiryKindLicense    = "license"
+	ExpiryKindCollection = "collection"
+	// ExpiryKindCollectionList targets the filter of a /collections listing
+	ExpiryKindCollectionList = "collection_list"
+	ExpiryKindTrending       = "trending"
+
+	ExpiryModeDelete  = "delete"
+	ExpiryModeRefresh = "refresh"
+)
+
+// MessageExpiry records when the buttons of a bot message stop being live, so
+// expiry survives restarts. Rows are removed once the message was handled.
+type MessageExpiry struct {
+	MessageID string    `gorm:"primaryKey;size:32"`
+	ChannelID string    `gorm:"size:32"`
+	GuildID   string    `gorm:"size:32"`
+	Kind      string    `gorm:"size:16"`
+	Target    string    `gorm:"size:128"`
+	Network   string    `gorm:"size:16"` // network of Target, empty for the default
+	OwnerID   string    `gorm:"size:32"`
+	ExpiresAt time.Time `gorm:"index"`
+	Attempts  int
+	CreatedAt time.Time
+}
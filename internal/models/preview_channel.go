@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PreviewChannel is a channel where a guild admin enabled automatic license
+// previews for IP ids posted in chat.
+type PreviewChannel struct {
+	ChannelID string `gorm:"primaryKey;size:32"`
+	GuildID   string `gorm:"size:32;index"`
+	EnabledBy string `gorm:"size:32"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// WalletLink ties a Discord user to a wallet. Address is only trusted once
+// the user signed the nonce challenge with that wallet. A re-link in progress
+// lives in PendingAddress and Nonce, so the verified wallet keeps working
+// until the new one is verified.
+type WalletLink struct {
+	UserID         string `gorm:"primaryKey;size:32"`
+	Address        string `gorm:"size:42;index"`
+	PendingAddress string `gorm:"size:42"`
+	Nonce          string `gorm:"size:64"`
+	NonceExpiresAt time.Time
+	Verified       bool
+	VerifiedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,204 @@
+// Package moderation evaluates SafeSearch-style likelihoods against a policy
+// of per-category thresholds.
+package moderation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Likelihood is an ordered SafeSearch likelihood. Zero is unknown.
+type Likelihood int
+
+const (
+	Unknown Likelihood = iota
+	VeryUnlikely
+	Unlikely
+	Possible
+	Likely
+	VeryLikely
+)
+
+var likelihoodNames = []string{"UNKNOWN", "VERY_UNLIKELY", "UNLIKELY", "POSSIBLE", "LIKELY", "VERY_LIKELY"}
+
+func (l Likelihood) String() string {
+	if l < Unknown || l > VeryLikely {
+		return likelihoodNames[Unknown]
+	}
+	return likelihoodNames[l]
+}
+
+// ParseLikelihood maps an API value such as "VERY_LIKELY" to a Likelihood.
+// Unrecognized values are Unknown.
+func ParseLikelihood(s string) Likelihood {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	// the API has been seen returning POSSIBLY
+	if s == "POSSIBLY" {
+		return Possible
+	}
+	for k, name := range likelihoodNames {
+		if name == s {
+			return Likelihood(k)
+		}
+	}
+	return Unknown
+}
+
+type Category string
+
+const (
+	Adult    Category = "adult"
+	Spoof    Category = "spoof"
+	Medical  Category = "medical"
+	Violence Category = "violence"
+	Racy     Category = "racy"
+)
+
+// Categories lists all categories in display order.
+var Categories = []Category{Adult, Spoof, Medical, Violence, Racy}
+
+type Verdict string
+
+const (
+	Safe   Verdict = "Safe"
+	Review Verdict = "Review"
+	Unsafe Verdict = "Unsafe"
+)
+
+// Rule holds the thresholds of one category; a level at or above a threshold
+// triggers it. Unknown disables a threshold.
+type Rule struct {
+	Review Likelihood
+	Block  Likelihood
+}
+
+// Policy maps categories to rules. Categories without a rule never trigger.
+type Policy map[Category]Rule
+
+// DefaultPolicy flags POSSIBLE for review and blocks LIKELY and above.
+func DefaultPolicy() Policy {
+	p := Policy{}
+	for _, c := range Categories {
+		p[c] = Rule{Review: Possible, Block: Likely}
+	}
+	return p
+}
+
+// String serializes the policy as "adult=POSSIBLE/LIKELY;racy=OFF/LIKELY".
+func (p Policy) String() string {
+	var parts []string
+	for _, c := range Categories {
+		r, ok := p[c]
+		if !ok {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s/%s", c, thresholdName(r.Review), thresholdName(r.Block)))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParsePolicy reads a String-serialized policy on top of DefaultPolicy.
+func ParsePolicy(s string) (Policy, error) {
+	p := DefaultPolicy()
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		cat, levels, ok := strings.Cut(part, "=")
+		review, block, ok2 := strings.Cut(levels, "/")
+		if !ok || !ok2 {
+			return p, fmt.Errorf("moderation: malformed rule %q", part)
+		}
+		c := Category(strings.ToLower(strings.TrimSpace(cat)))
+		if _, known := p[c]; !known {
+			return p, fmt.Errorf("moderation: unknown category %q", cat)
+		}
+		r, err := ParseRule(review, block)
+		if err != nil {
+			return p, err
+		}
+		p[c] = r
+	}
+	return p, nil
+}
+
+// ParseRule reads two thresholds, each a likelihood name or OFF. A review
+// threshold above the block threshold is rejected, as it could never trigger.
+func ParseRule(review, block string) (Rule, error) {
+	var r Rule
+	for _, t := range []struct {
+		raw string
+		dst *Likelihood
+	}{{review, &r.Review}, {block, &r.Block}} {
+		raw := strings.ToUpper(strings.TrimSpace(t.raw))
+		if raw == "OFF" || raw == "" {
+			continue
+		}
+		l := ParseLikelihood(raw)
+		if l == Unknown {
+			return r, fmt.Errorf("moderation: unknown likelihood %q", t.raw)
+		}
+		*t.dst = l
+	}
+	if r.Review != Unknown && r.Block != Unknown && r.Review > r.Block {
+		return r, fmt.Errorf("moderation: review threshold %s is above block threshold %s", r.Review, r.Block)
+	}
+	return r, nil
+}
+
+func thresholdName(l Likelihood) string {
+	if l == Unknown {
+		return "OFF"
+	}
+	return l.String()
+}
+
+// Trigger is a category that contributed to a verdict.
+type Trigger struct {
+	Category Category
+	Level    Likelihood
+	Verdict  Verdict
+}
+
+// Result is the outcome of evaluating levels against a policy.
+type Result struct {
+	Verdict  Verdict
+	Triggers []Trigger
+}
+
+// Evaluate returns Unsafe if any category reaches its block threshold, Review
+// if any reaches its review threshold, else Safe. Triggers are listed in
+// category order.
+func (p Policy) Evaluate(levels map[Category]string) Result {
+	res := Result{Verdict: Safe}
+	for _, c := range Categories {
+		r, ok := p[c]
+		if !ok {
+			continue
+		}
+		l := ParseLikelihood(levels[c])
+		if l == Unknown {
+			continue
+		}
+		switch {
+		case r.Block != Unknown && l >= r.Block:
+			res.Triggers = append(res.Triggers, Trigger{c, l, Unsafe})
+			res.Verdict = Unsafe
+		case r.Review != Unknown && l >= r.Review:
+			res.Triggers = append(res.Triggers, Trigger{c, l, Review})
+			if res.Verdict == Safe {
+				res.Verdict = Review
+			}
+		}
+	}
+	return res
+}
+
+// Categories returns the names of the triggering categories.
+func (r Result) Categories() []string {
+	out := make([]string, len(r.Triggers))
+	for k, t := range r.Triggers {
+		out[k] = string(t.Category)
+	}
+	return out
+}
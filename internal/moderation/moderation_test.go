@@ -0,0 +1,155 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLikelihood(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Likelihood
+	}{
+		{"VERY_UNLIKELY", VeryUnlikely},
+		{"UNLIKELY", Unlikely},
+		{"POSSIBLE", Possible},
+		{"POSSIBLY", Possible},
+		{"LIKELY", Likely},
+		{"VERY_LIKELY", VeryLikely},
+		{" likely ", Likely},
+		{"UNKNOWN", Unknown},
+		{"", Unknown},
+		{"MAYBE", Unknown},
+	}
+	for _, tt := range tests {
+		if got := ParseLikelihood(tt.in); got != tt.want {
+			t.Errorf("ParseLikelihood(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		levels   map[Category]string
+		want     Verdict
+		triggers []string
+	}{
+		{name: "nothing known", levels: map[Category]string{}, want: Safe, triggers: []string{}},
+		// a substring match on "LIKELY" used to treat these as unsafe
+		{name: "unlikely is safe", levels: map[Category]string{Adult: "UNLIKELY", Racy: "VERY_UNLIKELY"}, want: Safe, triggers: []string{}},
+		{name: "possible is review", levels: map[Category]string{Racy: "POSSIBLE"}, want: Review, triggers: []string{"racy"}},
+		{name: "likely is unsafe", levels: map[Category]string{Violence: "LIKELY"}, want: Unsafe, triggers: []string{"violence"}},
+		{name: "very likely is unsafe", levels: map[Category]string{Adult: "VERY_LIKELY"}, want: Unsafe, triggers: []string{"adult"}},
+		{name: "unsafe wins over review", levels: map[Category]string{Adult: "POSSIBLE", Spoof: "LIKELY"}, want: Unsafe, triggers: []string{"adult", "spoof"}},
+		{name: "unknown values are ignored", levels: map[Category]string{Medical: "UNKNOWN", Spoof: "garbage"}, want: Safe, triggers: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := DefaultPolicy().Evaluate(tt.levels)
+			if res.Verdict != tt.want {
+				t.Fatalf("verdict = %s, want %s", res.Verdict, tt.want)
+			}
+			if got := res.Categories(); !reflect.DeepEqual(got, tt.triggers) {
+				t.Fatalf("triggers = %v, want %v", got, tt.triggers)
+			}
+		})
+	}
+}
+
+func TestEvaluateCustomRules(t *testing.T) {
+	p := DefaultPolicy()
+	p[Racy] = Rule{Review: Unknown, Block: VeryLikely}
+	p[Spoof] = Rule{}
+	delete(p, Medical)
+
+	tests := []struct {
+		levels map[Category]string
+		want   Verdict
+	}{
+		{map[Category]string{Racy: "LIKELY"}, Safe},
+		{map[Category]string{Racy: "VERY_LIKELY"}, Unsafe},
+		{map[Category]string{Spoof: "VERY_LIKELY"}, Safe},
+		{map[Category]string{Medical: "VERY_LIKELY"}, Safe},
+	}
+	for _, tt := range tests {
+		if got := p.Evaluate(tt.levels).Verdict; got != tt.want {
+			t.Errorf("Evaluate(%v) = %s, want %s", tt.levels, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyStringRoundTrip(t *testing.T) {
+	p := DefaultPolicy()
+	p[Adult] = Rule{Review: Unlikely, Block: Possible}
+	p[Racy] = Rule{Review: Unknown, Block: VeryLikely}
+	p[Spoof] = Rule{}
+
+	s := p.String()
+	if want := "adult=UNLIKELY/POSSIBLE;spoof=OFF/OFF;medical=POSSIBLE/LIKELY;violence=POSSIBLE/LIKELY;racy=OFF/VERY_LIKELY"; s != want {
+		t.Fatalf("String() = %q, want %q", s, want)
+	}
+	got, err := ParsePolicy(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Fatalf("round trip = %v, want %v", got, p)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Policy
+		wantErr bool
+	}{
+		{name: "empty is default", in: "", want: DefaultPolicy()},
+		{name: "partial overrides default", in: "racy=off/very_likely", want: func() Policy {
+			p := DefaultPolicy()
+			p[Racy] = Rule{Block: VeryLikely}
+			return p
+		}()},
+		{name: "unknown category", in: "gore=POSSIBLE/LIKELY", wantErr: true},
+		{name: "malformed rule", in: "adult=POSSIBLE", wantErr: true},
+		{name: "unknown likelihood", in: "adult=SOMETIMES/LIKELY", wantErr: true},
+		{name: "review above block", in: "adult=VERY_LIKELY/POSSIBLE", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicy(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		review, block string
+		want          Rule
+		wantErr       bool
+	}{
+		{"POSSIBLE", "LIKELY", Rule{Possible, Likely}, false},
+		{"LIKELY", "LIKELY", Rule{Likely, Likely}, false},
+		{"OFF", "POSSIBLE", Rule{Unknown, Possible}, false},
+		{"VERY_LIKELY", "OFF", Rule{VeryLikely, Unknown}, false},
+		{"VERY_LIKELY", "POSSIBLE", Rule{}, true},
+		{"bogus", "LIKELY", Rule{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.review, tt.block)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRule(%q, %q) err = %v, wantErr %v", tt.review, tt.block, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseRule(%q, %q) = %+v, want %+v", tt.review, tt.block, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package network describes the Story networks the bot can query: their API
+// endpoint, chain and block explorer.
+package network
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/goldsheva/discord-story-bot/internal/configs"
+	"github.com/goldsheva/discord-story-bot/internal/explorer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Mainnet = "mainnet"
+	Aeneid  = "aeneid"
+)
+
+// Network is one Story deployment.
+type Network struct {
+	Name       string // key used in options and settings
+	Label      string // display name
+	APIBaseURL string
+	APIKey     string
+	ChainID    int
+	Explorer   explorer.Links // explorer and IP portal links
+}
+
+// Registry is an ordered set of networks; the first one is the default.
+// Positions are stable for a given configuration and are what custom_ids
+// carry.
+type Registry struct {
+	list []*Network
+}
+
+func NewRegistry(nets ...*Network) *Registry {
+	return &Registry{list: nets}
+}
+
+// All returns the networks in registry order.
+func (r *Registry) All() []*Network {
+	return r.list
+}
+
+func (r *Registry) Default() *Network {
+	return r.list[0]
+}
+
+// Lookup finds a network by name, case-insensitively.
+func (r *Registry) Lookup(name string) (*Network, bool) {
+	for _, n := range r.list {
+		if strings.EqualFold(n.Name, name) {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Index returns the position of n, 0 when it is not registered.
+func (r *Registry) Index(n *Network) uint8 {
+	for k, m := range r.list {
+		if m == n {
+			return uint8(k)
+		}
+	}
+	return 0
+}
+
+// At returns the network at position k, the default when out of range.
+func (r *Registry) At(k uint8) *Network {
+	if int(k) >= len(r.list) {
+		return r.Default()
+	}
+	return r.list[k]
+}
+
+var (
+	registry     *Registry
+	registryOnce sync.Once
+)
+
+// Networks is the registry built from the env configuration: mainnet always,
+// Aeneid when STORY_AENEID_API_BASE_URL is set. The mainnet explorer is
+// configurable; Aeneid always links to its own Storyscan.
+func Networks() *Registry {
+	registryOnce.Do(func() {
+		config := configs.GetEnvConfig()
+		links := func(kind, base, portal string) explorer.Links {
+			l, err := explorer.New(kind, base, portal)
+			if err != nil {
+				logrus.Fatalf("Can't configure explorer: %v", err)
+			}
+			return l
+		}
+		nets := []*Network{{
+			Name:       Mainnet,
+			Label:      "Story Mainnet",
+			APIBaseURL: config.STORY_API_BASE_URL,
+			APIKey:     config.STORY_API_KEY,
+			ChainID:    1514,
+			Explorer:   links(config.STORY_EXPLORER_KIND, config.STORY_EXPLORER_URL, config.STORY_IP_PORTAL_URL),
+		}}
+		if config.STORY_AENEID_API_BASE_URL != "" {
+			key := config.STORY_AENEID_API_KEY
+			if key == "" {
+				key = config.STORY_API_KEY
+			}
+			nets = append(nets, &Network{
+				Name:       Aeneid,
+				Label:      "Aeneid Testnet",
+				APIBaseURL: config.STORY_AENEID_API_BASE_URL,
+				APIKey:     key,
+				ChainID:    1315,
+				Explorer:   links(explorer.KindStoryscan, "https://aeneid.storyscan.io", "https://aeneid.explorer.story.foundation"),
+			})
+		}
+		registry = NewRegistry(nets...)
+	})
+	return registry
+}
@@ -0,0 +1,185 @@
+// Package risk combines moderation, infringement, dispute, metadata and
+// license signals of an IP asset into a 0–100 score with a breakdown.
+package risk
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+)
+
+// Level buckets a score for display.
+type Level string
+
+const (
+	Low      Level = "Low"
+	Medium   Level = "Medium"
+	High     Level = "High"
+	Critical Level = "Critical"
+)
+
+// DisputeState is the outcome of a single dispute against the IP.
+type DisputeState int
+
+const (
+	DisputeOpen DisputeState = iota
+	DisputeUpheld
+	DisputeDismissed
+)
+
+// Dispute statuses reported by the API. They follow the on-chain dispute
+// lifecycle, the same states the collection dispute counters are split by.
+const (
+	StatusRaised    = "RAISED"
+	StatusJudged    = "JUDGED"
+	StatusCancelled = "CANCELLED"
+	StatusResolved  = "RESOLVED"
+)
+
+// TagInDispute is the tag an IP carries while a dispute against it is open.
+const TagInDispute = "IN_DISPUTE"
+
+// ClassifyDispute reads a dispute's status and tags. A raised dispute is open.
+// A judged one is upheld when the IP got the tag the dispute was raised with
+// and dismissed otherwise. Cancelled disputes and resolved ones, whose tag was
+// lifted again, are dismissed. Unrecognized statuses fall back on the tags:
+// upheld when the target tag applies, else open.
+func ClassifyDispute(status, currentTag, targetTag string) DisputeState {
+	current, target := NormalizeTag(currentTag), NormalizeTag(targetTag)
+	tagged := target != "" && current == target
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case StatusRaised:
+		return DisputeOpen
+	case StatusJudged:
+		if tagged {
+			return DisputeUpheld
+		}
+		return DisputeDismissed
+	case StatusCancelled, StatusResolved:
+		return DisputeDismissed
+	}
+	if tagged {
+		return DisputeUpheld
+	}
+	return DisputeOpen
+}
+
+// NormalizeTag turns a dispute tag into its name. The API returns tags either
+// as names or as bytes32 hex of the name padded with zero bytes; an all-zero
+// tag is no tag.
+func NormalizeTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if len(tag) == 66 && strings.HasPrefix(tag, "0x") {
+		if b, err := hex.DecodeString(tag[2:]); err == nil {
+			return strings.ToUpper(strings.TrimRight(string(b), "\x00"))
+		}
+	}
+	return strings.ToUpper(tag)
+}
+
+// License is the part of the license terms that affects risk.
+type License struct {
+	CommercialUse       bool
+	DerivativesAllowed  bool
+	DerivativesApproval bool
+	AttributionRequired bool
+}
+
+// Input is everything known about an asset. Zero values mean "not known" or
+// "nothing found" as documented per field.
+type Input struct {
+	// Moderation is empty when the asset was never moderated.
+	Moderation moderation.Verdict
+	// InfringementChecked is false when no provider checked the asset.
+	InfringementChecked bool
+	Infringing          bool
+	Disputes            []DisputeState
+	// CollectionDisputes counts raised and judged disputes on the collection.
+	CollectionDisputes int
+	// MissingMetadata names absent metadata fields such as "title".
+	MissingMetadata []string
+	// License is nil when the asset has no license attached.
+	License *License
+}
+
+// Factor is one contribution to the score. Count is the number of items it
+// is based on, where that applies.
+type Factor struct {
+	Key    string
+	Points int
+	Count  int
+}
+
+// Score is the assessed risk with the factors that add up to it.
+type Score struct {
+	Value   int
+	Factors []Factor
+}
+
+// Level buckets the score.
+func (s Score) Level() Level {
+	switch {
+	case s.Value >= 75:
+		return Critical
+	case s.Value >= 50:
+		return High
+	case s.Value >= 25:
+		return Medium
+	}
+	return Low
+}
+
+// Assess scores in. Each signal is capped so that no single kind of finding
+// decides the score alone, and the total is capped at 100.
+func Assess(in Input) Score {
+	var s Score
+	add := func(key string, points, count int) {
+		if points > 0 {
+			s.Factors = append(s.Factors, Factor{Key: key, Points: points, Count: count})
+			s.Value += points
+		}
+	}
+
+	switch in.Moderation {
+	case moderation.Unsafe:
+		add("moderation_unsafe", 30, 0)
+	case moderation.Review:
+		add("moderation_review", 15, 0)
+	case "":
+		add("moderation_unknown", 5, 0)
+	}
+
+	switch {
+	case in.Infringing:
+		add("infringing", 30, 0)
+	case !in.InfringementChecked:
+		add("infringement_unchecked", 5, 0)
+	}
+
+	open, upheld := 0, 0
+	for _, d := range in.Disputes {
+		switch d {
+		case DisputeOpen:
+			open++
+		case DisputeUpheld:
+			upheld++
+		}
+	}
+	add("disputes_upheld", min(25*upheld, 40), upheld)
+	add("disputes_open", min(15*open, 30), open)
+	add("collection_disputes", min(5*in.CollectionDisputes, 15), in.CollectionDisputes)
+	add("missing_metadata", min(3*len(in.MissingMetadata), 10), len(in.MissingMetadata))
+
+	switch l := in.License; {
+	case l == nil:
+		add("no_license", 10, 0)
+	case l.CommercialUse && l.DerivativesAllowed && !l.DerivativesApproval:
+		add("permissive_license", 10, 0)
+	case l.CommercialUse && !l.AttributionRequired:
+		add("no_attribution", 5, 0)
+	}
+
+	s.Value = min(s.Value, 100)
+	return s
+}
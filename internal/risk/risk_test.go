@@ -0,0 +1,130 @@
+package risk
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+)
+
+// bytes32 encodes a tag name the way the dispute module stores it.
+func bytes32(name string) string {
+	b := make([]byte, 32)
+	copy(b, name)
+	return "0x" + hex.EncodeToString(b)
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{bytes32("IMPROPER_REGISTRATION"), "IMPROPER_REGISTRATION"},
+		{bytes32(TagInDispute), TagInDispute},
+		{bytes32(""), ""},
+		{"IMPROPER_USAGE", "IMPROPER_USAGE"},
+		{" improper_usage ", "IMPROPER_USAGE"},
+		{"", ""},
+		// short hex is not a bytes32 tag and is kept as is
+		{"0xabc", "0XABC"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTag(tt.in); got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyDispute(t *testing.T) {
+	target := bytes32("IMPROPER_REGISTRATION")
+	tests := []struct {
+		name    string
+		status  string
+		current string
+		target  string
+		want    DisputeState
+	}{
+		{"raised, in dispute", StatusRaised, bytes32(TagInDispute), target, DisputeOpen},
+		{"raised, lowercase status", "raised", bytes32(TagInDispute), target, DisputeOpen},
+		{"judged, tag applied", StatusJudged, target, target, DisputeUpheld},
+		{"judged, tag applied by name", StatusJudged, "IMPROPER_REGISTRATION", target, DisputeUpheld},
+		{"judged, tag cleared", StatusJudged, bytes32(""), target, DisputeDismissed},
+		{"cancelled", StatusCancelled, bytes32(""), target, DisputeDismissed},
+		{"resolved, tag lifted", StatusResolved, bytes32(""), target, DisputeDismissed},
+		{"resolved, tag still reported", StatusResolved, target, target, DisputeDismissed},
+		{"unknown status, tagged", "PENDING_APPEAL", target, target, DisputeUpheld},
+		{"unknown status, untagged", "", bytes32(TagInDispute), target, DisputeOpen},
+		{"no target tag never upholds", StatusJudged, bytes32(""), bytes32(""), DisputeDismissed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyDispute(tt.status, tt.current, tt.target); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssess(t *testing.T) {
+	clean := Input{
+		Moderation:          moderation.Safe,
+		InfringementChecked: true,
+		License:             &License{CommercialUse: true, DerivativesAllowed: true, DerivativesApproval: true, AttributionRequired: true},
+	}
+	tests := []struct {
+		name  string
+		in    func(Input) Input
+		value int
+		level Level
+	}{
+		{"clean", func(in Input) Input { return in }, 0, Low},
+		{"nothing known", func(Input) Input { return Input{} }, 20, Low},
+		{"moderation review", func(in Input) Input { in.Moderation = moderation.Review; return in }, 15, Low},
+		{"infringing and unsafe", func(in Input) Input {
+			in.Infringing, in.Moderation = true, moderation.Unsafe
+			return in
+		}, 60, High},
+		{"upheld disputes are capped", func(in Input) Input {
+			in.Disputes = []DisputeState{DisputeUpheld, DisputeUpheld, DisputeUpheld}
+			return in
+		}, 40, Medium},
+		{"dismissed disputes count nothing", func(in Input) Input {
+			in.Disputes = []DisputeState{DisputeDismissed, DisputeDismissed}
+			return in
+		}, 0, Low},
+		{"open disputes and collection", func(in Input) Input {
+			in.Disputes = []DisputeState{DisputeOpen}
+			in.CollectionDisputes = 10
+			return in
+		}, 30, Medium},
+		{"missing metadata is capped", func(in Input) Input {
+			in.MissingMetadata = []string{"title", "description", "image", "creator"}
+			return in
+		}, 10, Low},
+		{"permissive license", func(in Input) Input { in.License = &License{CommercialUse: true, DerivativesAllowed: true}; return in }, 10, Low},
+		{"commercial without attribution", func(in Input) Input { in.License = &License{CommercialUse: true}; return in }, 5, Low},
+		{"total is capped at 100", func(in Input) Input {
+			return Input{
+				Moderation:         moderation.Unsafe,
+				Infringing:         true,
+				Disputes:           []DisputeState{DisputeUpheld, DisputeUpheld, DisputeOpen, DisputeOpen},
+				CollectionDisputes: 5,
+			}
+		}, 100, Critical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Assess(tt.in(clean))
+			if s.Value != tt.value {
+				t.Fatalf("value = %d, want %d (factors %+v)", s.Value, tt.value, s.Factors)
+			}
+			if s.Level() != tt.level {
+				t.Fatalf("level = %s, want %s", s.Level(), tt.level)
+			}
+			sum := 0
+			for _, f := range s.Factors {
+				sum += f.Points
+			}
+			if tt.value < 100 && sum != s.Value {
+				t.Fatalf("factors add up to %d, score is %d", sum, s.Value)
+			}
+		})
+	}
+}
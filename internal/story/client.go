@@ -6,16 +6,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/goldsheva/discord-story-bot/internal/configs"
 	"github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/network"
 )
 
 type Client struct {
 	httpClient *http.Client
+	network    *network.Network
 	baseURL    string
 	apiKey     string
+	// moderated restricts asset queries to moderated content
+	moderated bool
+}
+
+// Network is the network the client queries.
+func (c *Client) Network() *network.Network {
+	return c.network
+}
+
+// BaseURL is the Story API endpoint the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
 }
 
 // APIError represents a structured error returned by Story API.
@@ -36,12 +51,19 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("story api error: status=%d", e.Status)
 }
 
+// NewClient returns a client for the default network.
 func NewClient() *Client {
+	return NewNetworkClient(network.Networks().Default())
+}
+
+func NewNetworkClient(n *network.Network) *Client {
 	config := configs.GetEnvConfig()
 	return &Client{
 		httpClient: &http.Client{Timeout: 15 * time.Second},
-		baseURL:    config.STORY_API_BASE_URL,
-		apiKey:     config.STORY_API_KEY,
+		network:    n,
+		baseURL:    n.APIBaseURL,
+		apiKey:     n.APIKey,
+		moderated:  config.STORY_MODERATED_ONLY,
 	}
 }
 
@@ -89,6 +111,21 @@ func (c *Client) doPost(path string, body interface{}, out interface{}) error {
 	return nil
 }
 
+// postAssets queries /assets as is. Lookups by id use it, so an asset asked
+// for explicitly is found whatever its moderation state.
+func (c *Client) postAssets(reqBody map[string]interface{}, out *dto.AssetsResponse) error {
+	return c.doPost("/assets", reqBody, out)
+}
+
+// listAssets queries /assets for listings, limited to moderated content when
+// configured.
+func (c *Client) listAssets(reqBody map[string]interface{}, out *dto.AssetsResponse) error {
+	if c.moderated {
+		reqBody["moderated"] = true
+	}
+	return c.postAssets(reqBody, out)
+}
+
 // GetAssetByID fetches IP asset(s) by ip id(s). It returns the first matching asset or nil if none.
 func (c *Client) GetAssetByID(ipID string) (*dto.IPAsset, error) {
 	reqBody := map[string]interface{}{
@@ -99,7 +136,7 @@ func (c *Client) GetAssetByID(ipID string) (*dto.IPAsset, error) {
 		"where":           map[string]interface{}{"ipIds": []string{ipID}},
 	}
 	var resp dto.AssetsResponse
-	if err := c.doPost("/assets", reqBody, &resp); err != nil {
+	if err := c.postAssets(reqBody, &resp); err != nil {
 		return nil, err
 	}
 	if len(resp.Data) == 0 {
@@ -108,6 +145,47 @@ func (c *Client) GetAssetByID(ipID string) (*dto.IPAsset, error) {
 	return &resp.Data[0], nil
 }
 
+// maxIPIDsPerRequest is the API limit for where.ipIds.
+const maxIPIDsPerRequest = 200
+
+// GetAssetsByIDs fetches many assets, splitting ipIDs into requests of at most
+// maxIPIDsPerRequest ids. Unknown ids are simply absent from the result.
+func (c *Client) GetAssetsByIDs(ipIDs []string) ([]dto.IPAsset, error) {
+	var out []dto.IPAsset
+	for start := 0; start < len(ipIDs); start += maxIPIDsPerRequest {
+		chunk := ipIDs[start:min(start+maxIPIDsPerRequest, len(ipIDs))]
+		reqBody := map[string]interface{}{
+			"orderBy":         "blockNumber",
+			"orderDirection":  "desc",
+			"pagination":      map[string]interface{}{"offset": 0, "limit": len(chunk)},
+			"includeLicenses": true,
+			"where":           map[string]interface{}{"ipIds": chunk},
+		}
+		var resp dto.AssetsResponse
+		if err := c.postAssets(reqBody, &resp); err != nil {
+			return nil, err
+		}
+		out = append(out, resp.Data...)
+	}
+	return out, nil
+}
+
+// GetAssetsByOwner lists IP assets owned by an address, newest first.
+func (c *Client) GetAssetsByOwner(owner string, limit, offset int) ([]dto.IPAsset, error) {
+	reqBody := map[string]interface{}{
+		"orderBy":         "blockNumber",
+		"orderDirection":  "desc",
+		"pagination":      map[string]interface{}{"offset": offset, "limit": limit},
+		"includeLicenses": true,
+		"where":           map[string]interface{}{"ownerAddress": owner},
+	}
+	var resp dto.AssetsResponse
+	if err := c.listAssets(reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
 // GetAssets is a generic fetch with custom where.
 func (c *Client) GetAssets(where map[string]interface{}) ([]dto.IPAsset, error) {
 	reqBody := map[string]interface{}{
@@ -118,14 +196,15 @@ func (c *Client) GetAssets(where map[string]interface{}) ([]dto.IPAsset, error)
 		"where":           where,
 	}
 	var resp dto.AssetsResponse
-	if err := c.doPost("/assets", reqBody, &resp); err != nil {
+	if err := c.listAssets(reqBody, &resp); err != nil {
 		return nil, err
 	}
 	return resp.Data, nil
 }
 
-// GetCollectionByAddress fetches collection metadata by contract address.
-func (c *Client) GetCollectionByAddress(address string) (*dto.CollectionMetadata, error) {
+// GetCollection fetches the enriched collection of a contract address: its
+// metadata together with asset, license and dispute counters.
+func (c *Client) GetCollection(address string) (*dto.CollectionItem, error) {
 	reqBody := map[string]interface{}{
 		"orderBy":        "updatedAt",
 		"orderDirection": "desc",
@@ -137,28 +216,137 @@ func (c *Client) GetCollectionByAddress(address string) (*dto.CollectionMetadata
 		return nil, err
 	}
 	if len(resp.Data) == 0 {
+		// nothing found
 		return nil, nil
 	}
-	return &resp.Data[0].CollectionMetadata, nil
+	return &resp.Data[0], nil
+}
+
+// GetCollectionByAddress fetches collection metadata by contract address.
+func (c *Client) GetCollectionByAddress(address string) (*dto.CollectionMetadata, error) {
+	item, err := c.GetCollection(address)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return &item.CollectionMetadata, nil
 }
 
 // GetCollectionDisputes fetches disputes counters for a collection contract address.
 func (c *Client) GetCollectionDisputes(address string) (*dto.CollectionItem, error) {
+	return c.GetCollection(address)
+}
+
+// ListCollections lists collections, optionally bounded by asset count
+// (negative bounds are ignored), ordered by last update in direction.
+func (c *Client) ListCollections(minAssets, maxAssets int, direction string, limit, offset int) ([]dto.CollectionItem, error) {
+	where := map[string]interface{}{}
+	if minAssets >= 0 {
+		where["minAssetCount"] = minAssets
+	}
+	if maxAssets >= 0 {
+		where["maxAssetCount"] = maxAssets
+	}
 	reqBody := map[string]interface{}{
 		"orderBy":        "updatedAt",
-		"orderDirection": "desc",
-		"pagination":     map[string]interface{}{"offset": 0},
-		"where":          map[string]interface{}{"collectionAddresses": []string{address}},
+		"orderDirection": direction,
+		"pagination":     map[string]interface{}{"offset": offset, "limit": limit},
+		"where":          where,
 	}
 	var resp dto.CollectionsResponse
 	if err := c.doPost("/collections", reqBody, &resp); err != nil {
 		return nil, err
 	}
-	if len(resp.Data) == 0 {
-		// nothing found
-		return nil, nil
+	return resp.Data, nil
+}
+
+// LatestAssets lists the most recently registered IP assets, newest first,
+// skipping offset of them. moderated restricts them to moderated content
+// regardless of the client default.
+func (c *Client) LatestAssets(limit, offset int, moderated bool) ([]dto.IPAsset, error) {
+	reqBody := map[string]interface{}{
+		"orderBy":         "createdAt",
+		"orderDirection":  "desc",
+		"pagination":      map[string]interface{}{"offset": offset, "limit": limit},
+		"includeLicenses": true,
 	}
-	return &resp.Data[0], nil
+	if moderated {
+		reqBody["moderated"] = true
+	}
+	var resp dto.AssetsResponse
+	if err := c.listAssets(reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// TopAssetsByDescendants lists the most remixed IP assets, optionally only
+// those of owner.
+func (c *Client) TopAssetsByDescendants(owner string, limit int) ([]dto.IPAsset, error) {
+	reqBody := map[string]interface{}{
+		"orderBy":         "descendantCount",
+		"orderDirection":  "desc",
+		"pagination":      map[string]interface{}{"offset": 0, "limit": limit},
+		"includeLicenses": true,
+	}
+	if owner != "" {
+		reqBody["where"] = map[string]interface{}{"ownerAddress": owner}
+	}
+	var resp dto.AssetsResponse
+	if err := c.listAssets(reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// ListAssets pages through all IP assets, newest first.
+func (c *Client) ListAssets(limit, offset int) ([]dto.IPAsset, error) {
+	reqBody := map[string]interface{}{
+		"orderBy":         "blockNumber",
+		"orderDirection":  "desc",
+		"pagination":      map[string]interface{}{"offset": offset, "limit": limit},
+		"includeLicenses": true,
+	}
+	var resp dto.AssetsResponse
+	if err := c.listAssets(reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// maxDisputesPerRequest is the page size limit of /disputes.
+const maxDisputesPerRequest = 200
+
+// GetDisputesByIP lists disputes raised against an IP asset, newest first.
+func (c *Client) GetDisputesByIP(ipID string) ([]dto.Dispute, error) {
+	reqBody := map[string]interface{}{
+		"options": map[string]interface{}{
+			"orderBy":        "blockNumber",
+			"orderDirection": "desc",
+			"pagination":     map[string]interface{}{"limit": maxDisputesPerRequest},
+			"where":          map[string]interface{}{"targetIpId": ipID},
+		},
+	}
+	var resp dto.DisputesResponse
+	if err := c.doPost("/disputes", reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// SearchAssets runs a full-text search over IP assets. mediaType may be empty.
+func (c *Client) SearchAssets(query, mediaType string, limit int) ([]dto.IPSearchResult, error) {
+	reqBody := map[string]interface{}{
+		"query":      query,
+		"pagination": map[string]interface{}{"offset": 0, "limit": limit},
+	}
+	if mediaType != "" {
+		reqBody["mediaType"] = mediaType
+	}
+	var resp dto.SearchResponse
+	if err := c.doPost("/search", reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
 }
 
 // GetCollectionMedia fetches media fields for a collection contract address.
@@ -173,7 +361,12 @@ func (c *Client) GetAssetTerms(ipID string) (*dto.LicenseTermsWrapper, error) {
 	if asset == nil {
 		return nil, nil
 	}
-	// Prefer primary LicenseTemplate, else try the first item in Licenses array
+	return PrimaryLicense(asset), nil
+}
+
+// PrimaryLicense picks the license shown for an asset: the primary LicenseTemplate,
+// else the first item in Licenses. It returns nil when the asset has no license.
+func PrimaryLicense(asset *dto.IPAsset) *dto.LicenseTermsWrapper {
 	var lt *dto.LicenseTermsWrapper
 	if asset.LicenseTemplate != nil {
 		lt = asset.LicenseTemplate
@@ -181,13 +374,71 @@ func (c *Client) GetAssetTerms(ipID string) (*dto.LicenseTermsWrapper, error) {
 		lt = &asset.Licenses[0]
 	}
 	if lt == nil {
-		return nil, nil
+		return nil
 	}
 	// normalize AttributionRequired from available attribution flags
 	if lt.Terms != nil {
 		lt.Terms.AttributionRequired = lt.Terms.DerivativesAttribution || lt.Terms.CommercialAttribution
 	}
-	return lt, nil
+	return lt
+}
+
+// LicenseField is one named value of a license, in display form.
+type LicenseField struct {
+	Name  string
+	Value string
+}
+
+// LicenseFields flattens a license into its template, all PIL terms and the
+// licensing config, in a stable order. Absent blocks yield no fields.
+func LicenseFields(lt *dto.LicenseTermsWrapper) []LicenseField {
+	if lt == nil {
+		return nil
+	}
+	b := strconv.FormatBool
+	out := []LicenseField{
+		{"templateName", lt.TemplateName},
+		{"licenseTemplateId", lt.LicenseTemplateId},
+		{"licenseTermsId", lt.LicenseTermsId},
+	}
+	if t := lt.Terms; t != nil {
+		out = append(out,
+			LicenseField{"transferable", b(t.Transferable)},
+			LicenseField{"commercialUse", b(t.CommercialUse)},
+			LicenseField{"commercialAttribution", b(t.CommercialAttribution)},
+			LicenseField{"commercialRevShare", strconv.Itoa(t.CommercialRevShare)},
+			LicenseField{"commercialRevCeiling", t.CommercialRevCeiling},
+			LicenseField{"commercializerChecker", t.CommercializerChecker},
+			LicenseField{"commercializerCheckerData", t.CommercializerCheckerData},
+			LicenseField{"currency", t.Currency},
+			LicenseField{"defaultMintingFee", t.DefaultMintingFee},
+			LicenseField{"derivativesAllowed", b(t.DerivativesAllowed)},
+			LicenseField{"derivativesApproval", b(t.DerivativesApproval)},
+			LicenseField{"derivativesAttribution", b(t.DerivativesAttribution)},
+			LicenseField{"derivativesReciprocal", b(t.DerivativesReciprocal)},
+			LicenseField{"derivativeRevCeiling", t.DerivativeRevCeiling},
+			LicenseField{"expiration", t.Expiration},
+			LicenseField{"royaltyPolicy", t.RoyaltyPolicy},
+			LicenseField{"uri", t.URI},
+		)
+	}
+	if c := lt.LicensingConfig; c != nil {
+		fee := ""
+		if c.MintingFee != nil {
+			fee = c.MintingFee.String()
+		}
+		out = append(out,
+			LicenseField{"licensingConfig.isSet", b(c.IsSet)},
+			LicenseField{"licensingConfig.disabled", b(c.Disabled)},
+			LicenseField{"licensingConfig.mintingFee", fee},
+			LicenseField{"licensingConfig.commercialRevShare", strconv.Itoa(c.CommercialRevShare)},
+			LicenseField{"licensingConfig.licensingHook", c.LicensingHook},
+			LicenseField{"licensingConfig.hookData", c.HookData},
+			LicenseField{"licensingConfig.expectMinimumGroupRewardShare", strconv.Itoa(c.ExpectMinimumGroupRewardShare)},
+			LicenseField{"licensingConfig.expectGroupRewardPool", c.ExpectGroupRewardPool},
+		)
+	}
+	return out
 }
 
 func (c *Client) GetAssetInfringement(ipID string) ([]dto.InfringementStatus, error) {
@@ -220,11 +471,31 @@ func (c *Client) GetAssetMint(ipID string) (*dto.NFTMint, error) {
 	if asset == nil {
 		return nil, nil
 	}
-	// Prefer mint present under NFTMetadata (per openapi) and fallback to top-level
+	return AssetMint(asset), nil
+}
+
+// AssetMint prefers mint present under NFTMetadata (per openapi) and falls back to top-level.
+func AssetMint(asset *dto.IPAsset) *dto.NFTMint {
 	if asset.NFTMetadata != nil && asset.NFTMetadata.Mint != nil {
-		return asset.NFTMetadata.Mint, nil
+		return asset.NFTMetadata.Mint
+	}
+	return asset.Mint
+}
+
+// AssetContract prefers nested nftMetadata.contract per openapi and falls back to top-level.
+func AssetContract(asset *dto.IPAsset) *dto.ContractInfo {
+	if asset.NFTMetadata != nil && asset.NFTMetadata.Contract != nil {
+		return asset.NFTMetadata.Contract
+	}
+	return asset.Contract
+}
+
+// AssetCollection prefers nested nftMetadata.collection per openapi and falls back to top-level.
+func AssetCollection(asset *dto.IPAsset) *dto.CollectionInfo {
+	if asset.NFTMetadata != nil && asset.NFTMetadata.Collection != nil {
+		return asset.NFTMetadata.Collection
 	}
-	return asset.Mint, nil
+	return asset.Collection
 }
 
 func (c *Client) GetAssetCollection(ipID string) (*dto.CollectionInfo, error) {
@@ -0,0 +1,87 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// IsAddress reports whether s looks like an EVM address.
+func IsAddress(s string) bool {
+	return addressPattern.MatchString(s)
+}
+
+// NewNonce returns a random hex nonce for a link challenge.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// LinkMessage is the text a user signs with personal_sign to prove ownership.
+func LinkMessage(userID, address, nonce string) string {
+	return fmt.Sprintf("Link Discord account %s to wallet %s\nNonce: %s", userID, strings.ToLower(address), nonce)
+}
+
+// RecoverAddress returns the address that produced an EIP-191 personal_sign
+// signature of message. sigHex is the 65-byte r||s||v signature in hex.
+func RecoverAddress(message, sigHex string) (string, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(sigHex), "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid signature hex: %w", err)
+	}
+	if len(sig) != 65 {
+		return "", errors.New("signature must be 65 bytes")
+	}
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", errors.New("invalid signature recovery id")
+	}
+
+	// compact format expected by ecdsa.RecoverCompact: <27+recid><R><S>
+	compact := make([]byte, 65)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+
+	pub, _, err := ecdsa.RecoverCompact(compact, personalHash(message))
+	if err != nil {
+		return "", err
+	}
+	// address is the last 20 bytes of keccak256 over the uncompressed key without prefix
+	return "0x" + hex.EncodeToString(keccak256(pub.SerializeUncompressed()[1:])[12:]), nil
+}
+
+// Verify reports whether sigHex is a signature of message by address.
+func Verify(address, message, sigHex string) (bool, error) {
+	recovered, err := RecoverAddress(message, sigHex)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(recovered, address), nil
+}
+
+func personalHash(message string) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return keccak256([]byte(prefix), []byte(message))
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
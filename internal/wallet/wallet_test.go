@@ -0,0 +1,66 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+)
+
+// personal_sign vector from the web3.js accounts.sign documentation:
+// private key 0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318
+// signing "Some data".
+const (
+	vectorAddress   = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	vectorMessage   = "Some data"
+	vectorSignature = "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+)
+
+func TestRecoverAddress(t *testing.T) {
+	got, err := RecoverAddress(vectorMessage, vectorSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.EqualFold(got, vectorAddress) {
+		t.Fatalf("got %s, want %s", got, vectorAddress)
+	}
+	// recovery ids 0/1 are accepted as well as 27/28
+	if got, err := RecoverAddress(vectorMessage, strings.TrimSuffix(vectorSignature, "1c")+"01"); err != nil || !strings.EqualFold(got, vectorAddress) {
+		t.Fatalf("v=1: got %s, %v", got, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		message string
+		sig     string
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid", address: vectorAddress, message: vectorMessage, sig: vectorSignature, want: true},
+		{name: "address case-insensitive", address: strings.ToLower(vectorAddress), message: vectorMessage, sig: vectorSignature, want: true},
+		{name: "no 0x prefix", address: vectorAddress, message: vectorMessage, sig: strings.TrimPrefix(vectorSignature, "0x"), want: true},
+		{name: "other address", address: "0x0000000000000000000000000000000000000001", message: vectorMessage, sig: vectorSignature},
+		{name: "other message", address: vectorAddress, message: "Some data!", sig: vectorSignature},
+		{name: "bad hex", address: vectorAddress, message: vectorMessage, sig: "0xzz", wantErr: true},
+		{name: "short", address: vectorAddress, message: vectorMessage, sig: vectorSignature[:len(vectorSignature)-2], wantErr: true},
+		{name: "bad recovery id", address: vectorAddress, message: vectorMessage, sig: strings.TrimSuffix(vectorSignature, "1c") + "1f", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.address, tt.message, tt.sig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkMessageLowercasesAddress(t *testing.T) {
+	if got := LinkMessage("1", vectorAddress, "n"); !strings.Contains(got, strings.ToLower(vectorAddress)) {
+		t.Fatalf("address not normalized: %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+package workers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+)
+
+const (
+	// Discord allows at most 25 choices and expects an answer within 3s
+	maxAutocompleteChoices = 25
+	autocompleteSearchWait = 2 * time.Second
+	minSearchQueryLen      = 3
+)
+
+// handleAutocomplete suggests values for ip_id and address options from the
+// user's lookup history and, for IP ids, from title search.
+func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	data := i.ApplicationCommandData()
+	focused := focusedOption(data.Options)
+	if focused == nil {
+		respondAutocomplete(s, i, nil)
+		return
+	}
+	query := strings.TrimSpace(focused.StringValue())
+
+	var kind string
+	switch focused.Name {
+	case "ip_id", "ip_a", "ip_b":
+		kind = models.LookupKindIP
+	case "address":
+		kind = models.LookupKindCollection
+	default:
+		respondAutocomplete(s, i, nil)
+		return
+	}
+
+	// start search early so it overlaps with the history query
+	var searchCh chan []dto.IPSearchResult
+	if kind == models.LookupKindIP && len(query) >= minSearchQueryLen && !strings.HasPrefix(strings.ToLower(query), "0x") {
+		searchCh = make(chan []dto.IPSearchResult, 1)
+		go func() {
+			res, err := client.SearchAssets(query, "", maxAutocompleteChoices)
+			if err != nil {
+				log.Debugf("autocomplete search failed: %v", err)
+			}
+			searchCh <- res
+		}()
+	}
+
+	seen := map[string]struct{}{}
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	add := func(icon, label, value string) {
+		if _, ok := seen[value]; ok || value == "" || len(choices) >= maxAutocompleteChoices {
+			return
+		}
+		seen[value] = struct{}{}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: icon + " " + choiceName(label, value), Value: value})
+	}
+
+	if uid := interactionUserID(i); uid != "" {
+		recent, err := database.RecentLookups(uid, interactionNetwork(i).Name, kind, query, maxAutocompleteChoices)
+		if err != nil {
+			log.Debugf("autocomplete history failed: %v", err)
+		}
+		for _, h := range recent {
+			add("🕘", h.Label, h.Value)
+		}
+	}
+
+	if searchCh != nil {
+		select {
+		case res := <-searchCh:
+			for _, r := range res {
+				add("🔎", r.Title, r.IpId)
+			}
+		case <-time.After(autocompleteSearchWait):
+			log.Debug("autocomplete search timed out")
+		}
+	}
+
+	respondAutocomplete(s, i, choices)
+}
+
+func focusedOption(opts []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, o := range opts {
+		if o.Focused {
+			return o
+		}
+		if f := focusedOption(o.Options); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func respondAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}); err != nil {
+		log.Debugf("failed to respond to autocomplete: %v", err)
+	}
+}
+
+// choiceName renders "label — 0x1234…abcd" within Discord's 100 char limit.
+func choiceName(label, value string) string {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return truncateRunes(value, 95)
+	}
+	short := value
+	if len(value) > 14 {
+		short = fmt.Sprintf("%s…%s", value[:6], value[len(value)-4:])
+	}
+	return truncateRunes(label, 80) + " — " + short
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// recordLookup stores a successful lookup on the interaction's network for
+// autocomplete in the background.
+func recordLookup(i *discordgo.InteractionCreate, kind, value, label string) {
+	uid := interactionUserID(i)
+	if uid == "" || value == "" {
+		return
+	}
+	networkName := interactionNetwork(i).Name
+	go func() {
+		if err := database.RecordLookup(uid, networkName, kind, value, label); err != nil {
+			log.Debugf("failed to record lookup: %v", err)
+		}
+	}()
+}
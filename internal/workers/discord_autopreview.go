@@ -0,0 +1,202 @@
+package workers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/configs"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+)
+
+// maxPreviewsPerMessage caps how many cards one chat message can trigger.
+const maxPreviewsPerMessage = 3
+
+var (
+	previewChannels   = map[string]struct{}{}
+	previewChannelsMu sync.RWMutex
+)
+
+// loadPreviewChannels warms the in-memory set of opted-in channels.
+func loadPreviewChannels() {
+	ids, err := database.ListPreviewChannels("")
+	if err != nil {
+		log.Errorf("failed to load auto-preview channels: %v", err)
+		return
+	}
+	previewChannelsMu.Lock()
+	previewChannels = map[string]struct{}{}
+	for _, id := range ids {
+		previewChannels[id] = struct{}{}
+	}
+	previewChannelsMu.Unlock()
+}
+
+func isPreviewChannel(channelID string) bool {
+	previewChannelsMu.RLock()
+	defer previewChannelsMu.RUnlock()
+	_, ok := previewChannels[channelID]
+	return ok
+}
+
+func setPreviewChannel(guildID, channelID, userID string, enabled bool) error {
+	if err := database.SetPreviewChannel(guildID, channelID, userID, enabled); err != nil {
+		return err
+	}
+	previewChannelsMu.Lock()
+	if enabled {
+		previewChannels[channelID] = struct{}{}
+	} else {
+		delete(previewChannels, channelID)
+	}
+	previewChannelsMu.Unlock()
+	return nil
+}
+
+// previewLimiter enforces a per-channel cooldown and skips IP ids already
+// previewed in the same channel within the dedup window.
+type previewLimiter struct {
+	mu       sync.Mutex
+	cooldown time.Duration
+	dedup    time.Duration
+	channels map[string]time.Time
+	seen     map[string]time.Time
+}
+
+func newPreviewLimiter(cooldown, dedup time.Duration) *previewLimiter {
+	return &previewLimiter{
+		cooldown: cooldown,
+		dedup:    dedup,
+		channels: map[string]time.Time{},
+		seen:     map[string]time.Time{},
+	}
+}
+
+// allow returns the ids that may be previewed now and starts the channel
+// cooldown. The ids only count as previewed once commit records them, so a
+// failed lookup or post doesn't suppress them for the dedup window.
+func (l *previewLimiter) allow(channelID string, ids []string, now time.Time) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.channels[channelID]; ok && now.Sub(last) < l.cooldown {
+		return nil
+	}
+	var out []string
+	for _, id := range ids {
+		key := channelID + ":" + strings.ToLower(id)
+		if last, ok := l.seen[key]; ok && now.Sub(last) < l.dedup {
+			continue
+		}
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	l.channels[channelID] = now
+	l.prune(now)
+	return out
+}
+
+// commit marks ids as previewed in the channel.
+func (l *previewLimiter) commit(channelID string, ids []string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		l.seen[channelID+":"+strings.ToLower(id)] = now
+	}
+}
+
+func (l *previewLimiter) prune(now time.Time) {
+	for k, t := range l.seen {
+		if now.Sub(t) >= l.dedup {
+			delete(l.seen, k)
+		}
+	}
+	for k, t := range l.channels {
+		if now.Sub(t) >= l.cooldown {
+			delete(l.channels, k)
+		}
+	}
+}
+
+// guildInteraction wraps a guild id into an interaction so event handlers can
+// reuse the localized embed builders and the settings lookup.
+func guildInteraction(guildID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{GuildID: guildID}}
+}
+
+// handleMessageCreate replies with license previews for IP ids posted in opted-in channels.
+func handleMessageCreate() func(s *discordgo.Session, m *discordgo.MessageCreate) {
+	config := configs.GetEnvConfig()
+	limiter := newPreviewLimiter(
+		time.Duration(config.AUTO_PREVIEW_COOLDOWN_SEC)*time.Second,
+		time.Duration(config.AUTO_PREVIEW_DEDUP_SEC)*time.Second,
+	)
+
+	return func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.Author == nil || m.Author.Bot || m.GuildID == "" || !isPreviewChannel(m.ChannelID) {
+			return
+		}
+		// cap after dedup so recently previewed ids don't use up the slots
+		ids := limiter.allow(m.ChannelID, extractIPIDs(m.Content), time.Now())
+		if len(ids) == 0 {
+			return
+		}
+		if len(ids) > maxPreviewsPerMessage {
+			ids = ids[:maxPreviewsPerMessage]
+		}
+
+		assets, err := fetchAssets(networkClient(guildNetwork(m.GuildID)), ids)
+		if err != nil {
+			log.Debugf("auto-preview lookup failed: %v", err)
+			return
+		}
+		if len(assets) == 0 {
+			return
+		}
+
+		ctx := guildInteraction(m.GuildID)
+		st := resolveSettings(ctx)
+		var embeds []*discordgo.MessageEmbed
+		posted := make([]string, 0, len(assets))
+		for k := range assets {
+			embed := previewLicenseEmbed(ctx, &assets[k])
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: st.FooterText}
+			embeds = append(embeds, embed)
+			posted = append(posted, assets[k].IpId)
+		}
+		if _, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+			Embeds:          embeds,
+			Reference:       m.Reference(),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		}); err != nil {
+			log.Debugf("failed to send auto-preview: %v", err)
+			return
+		}
+		limiter.commit(m.ChannelID, posted, time.Now())
+	}
+}
+
+// previewLicenseEmbed is a small card: title, IP id and inline license flags.
+func previewLicenseEmbed(i *discordgo.InteractionCreate, asset *dto.IPAsset) *discordgo.MessageEmbed {
+	title := asset.IpId
+	if asset.Title != "" {
+		title = truncateRunes(asset.Title, 256)
+	}
+	embed := &discordgo.MessageEmbed{Title: title, Description: asset.IpId, Color: accentColor(i, 0x00FF00), URL: explorerLinks(i).IP(asset.IpId)}
+	if lt := storyclient.PrimaryLicense(asset); lt != nil && lt.Terms != nil {
+		yes := map[bool]string{true: "✅", false: "❌"}
+		embed.Fields = append(embed.Fields,
+			&discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_commercial_use"), Value: yes[lt.Terms.CommercialUse], Inline: true},
+			&discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_derivatives_allowed"), Value: yes[lt.Terms.DerivativesAllowed], Inline: true},
+			&discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_commercial_rev_share"), Value: formatRevSharePercent(lt.Terms.CommercialRevShare), Inline: true},
+		)
+	} else {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "title_terms"), Value: getTextWithCtx(i, "no_terms")})
+	}
+	return embed
+}
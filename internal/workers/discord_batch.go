@@ -0,0 +1,262 @@
+package workers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	licenseBatchCommand = "license_batch"
+	// maxBatchIDs caps one batch; the client splits it into API-sized chunks
+	maxBatchIDs = 500
+	// maxBatchFileSize bounds the attachment we are willing to download
+	maxBatchFileSize = 1 << 20
+	// batchTableRows is how many rows fit the summary embed, the CSV has all
+	batchTableRows = 25
+)
+
+var batchFileClient = &http.Client{Timeout: 10 * time.Second}
+
+func licenseBatchCommandDef() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        licenseBatchCommand,
+		Description: "Check licenses of many IP IDs at once",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "ip_ids", Description: "IP IDs separated by spaces or commas"},
+			{Type: discordgo.ApplicationCommandOptionAttachment, Name: "file", Description: "A .txt or .csv file with IP IDs"},
+			privateOption,
+		},
+	}
+}
+
+// batchRow is one line of the batch report.
+type batchRow struct {
+	IPID         string
+	Title        string
+	Found        bool
+	Commercial   string
+	Derivatives  string
+	RevShare     string
+	Moderation   string
+	Infringement infringementState
+}
+
+func handleLicenseBatch(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	data := i.ApplicationCommandData()
+	var text strings.Builder
+	var attachment *discordgo.MessageAttachment
+	for _, o := range data.Options {
+		switch o.Name {
+		case "ip_ids":
+			text.WriteString(o.StringValue())
+		case "file":
+			if id, ok := o.Value.(string); ok && data.Resolved != nil {
+				attachment = data.Resolved.Attachments[id]
+			}
+		}
+	}
+	if text.Len() == 0 && attachment == nil {
+		respondEphemeral(s, i, getTextWithCtx(i, "missing_param"))
+		return
+	}
+	if attachment != nil {
+		ext := strings.ToLower(path.Ext(attachment.Filename))
+		if ext != ".txt" && ext != ".csv" {
+			respondEphemeral(s, i, getTextWithCtx(i, "batch_bad_file"))
+			return
+		}
+	}
+
+	// downloading the file may take a while, acknowledge first
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	if attachment != nil {
+		body, err := downloadAttachment(attachment)
+		if err != nil {
+			followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+			return
+		}
+		text.WriteString("\n")
+		text.Write(body)
+	}
+	ids := extractIPIDs(text.String())
+	if len(ids) == 0 {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Description: getTextWithCtx(i, "batch_no_ids"), Color: 0xFFFF00})
+		return
+	}
+	total := len(ids)
+	if total > maxBatchIDs {
+		ids = ids[:maxBatchIDs]
+	}
+
+	assets, err := fetchAssets(client, ids)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	byID := map[string]*dto.IPAsset{}
+	for k := range assets {
+		byID[strings.ToLower(assets[k].IpId)] = &assets[k]
+	}
+	rows := make([]batchRow, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, newBatchRow(i, id, byID[strings.ToLower(id)]))
+	}
+
+	csvData, err := batchCSV(rows)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	embed := batchSummaryEmbed(i, rows, total)
+	applyEmbedDefaults(i, embed)
+	params := &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  []*discordgo.File{{Name: "licenses.csv", ContentType: "text/csv", Reader: bytes.NewReader(csvData)}},
+	}
+	if isEphemeral(i) {
+		params.Flags = discordgo.MessageFlagsEphemeral
+	}
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, params); err != nil {
+		logrus.Error("Failed to send batch report: ", err)
+	}
+}
+
+func downloadAttachment(a *discordgo.MessageAttachment) ([]byte, error) {
+	if a.Size > maxBatchFileSize {
+		return nil, fmt.Errorf("file is larger than %d KiB", maxBatchFileSize>>10)
+	}
+	resp, err := batchFileClient.Get(a.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: status %d", a.Filename, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBatchFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxBatchFileSize {
+		return nil, errors.New("file is too large")
+	}
+	return body, nil
+}
+
+func newBatchRow(i *discordgo.InteractionCreate, id string, asset *dto.IPAsset) batchRow {
+	row := batchRow{IPID: id, Commercial: "-", Derivatives: "-", RevShare: "-", Moderation: "-"}
+	if asset == nil {
+		return row
+	}
+	row.Found = true
+	row.Title = asset.Title
+	yes := map[bool]string{true: "yes", false: "no"}
+	if lt := storyclient.PrimaryLicense(asset); lt != nil && lt.Terms != nil {
+		row.Commercial = yes[lt.Terms.CommercialUse]
+		row.Derivatives = yes[lt.Terms.DerivativesAllowed]
+		row.RevShare = formatRevSharePercent(lt.Terms.CommercialRevShare)
+	}
+	if asset.Moderation != nil {
+		row.Moderation = string(evaluateModeration(i, asset.Moderation).Verdict)
+	}
+	if len(asset.Infringement) > 0 {
+		row.Infringement = infringementStateOf(asset.Infringement)
+	}
+	return row
+}
+
+func batchCSV(rows []batchRow) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	_ = w.Write([]string{"ip_id", "title", "found", "commercial_use", "derivatives_allowed", "commercial_rev_share", "moderation", "infringement"})
+	for _, r := range rows {
+		_ = w.Write([]string{r.IPID, r.Title, fmt.Sprint(r.Found), r.Commercial, r.Derivatives, r.RevShare, r.Moderation, infringementCode(r.Infringement)})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// infringementCode is the stable value of an infringement state used in the
+// batch table and CSV.
+func infringementCode(s infringementState) string {
+	switch s {
+	case infringementFound:
+		return "yes"
+	case infringementPending:
+		return "check"
+	case infringementClear:
+		return "no"
+	}
+	return "-"
+}
+
+// batchSummaryEmbed renders the first rows as a monospace table with totals.
+// total is the number of IDs given, which is more than len(rows) when the
+// batch was cut to maxBatchIDs.
+func batchSummaryEmbed(i *discordgo.InteractionCreate, rows []batchRow, total int) *discordgo.MessageEmbed {
+	found, commercial, unsafe, infringing := 0, 0, 0, 0
+	for _, r := range rows {
+		if r.Found {
+			found++
+		}
+		if r.Commercial == "yes" {
+			commercial++
+		}
+		if r.Moderation == string(moderation.Unsafe) {
+			unsafe++
+		}
+		if r.Infringement == infringementFound {
+			infringing++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("```\n")
+	fmt.Fprintf(&b, "%-13s %-4s %-4s %-7s %-7s %s\n", "IP", "Com", "Der", "Rev", "Mod", "Infr")
+	for k, r := range rows {
+		if k == batchTableRows {
+			break
+		}
+		short := r.IPID[:6] + "..." + r.IPID[len(r.IPID)-4:]
+		if !r.Found {
+			fmt.Fprintf(&b, "%-13s %s\n", short, getTextWithCtx(i, "not_found"))
+			continue
+		}
+		fmt.Fprintf(&b, "%-13s %-4s %-4s %-7s %-7s %s\n", short, r.Commercial, r.Derivatives, r.RevShare, r.Moderation, infringementCode(r.Infringement))
+	}
+	b.WriteString("```")
+	if len(rows) > batchTableRows {
+		fmt.Fprintf(&b, "\n"+getTextWithCtx(i, "batch_more_rows"), len(rows)-batchTableRows)
+	}
+	if total > len(rows) {
+		fmt.Fprintf(&b, "\n"+getTextWithCtx(i, "batch_truncated"), len(rows), total)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s — %d", getTextWithCtx(i, "title_batch"), len(rows)),
+		Description: b.String(),
+		Color:       accentColor(i, 0x00AAFF),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: getTextWithCtx(i, "batch_found"), Value: fmt.Sprintf("%d/%d", found, len(rows)), Inline: true},
+			{Name: getTextWithCtx(i, "embed_commercial_use"), Value: fmt.Sprint(commercial), Inline: true},
+			{Name: getTextWithCtx(i, "batch_unsafe"), Value: fmt.Sprint(unsafe), Inline: true},
+			{Name: getTextWithCtx(i, "title_infringement"), Value: fmt.Sprint(infringing), Inline: true},
+		},
+	}
+}
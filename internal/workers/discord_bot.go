@@ -2,16 +2,20 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/goldsheva/discord-story-bot/internal/configs"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
 	dto "github.com/goldsheva/discord-story-bot/internal/dto"
 	i18n_pkg "github.com/goldsheva/discord-story-bot/internal/i18n"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+	"github.com/goldsheva/discord-story-bot/internal/network"
 	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
 	"github.com/sirupsen/logrus"
 )
@@ -19,7 +23,11 @@ import (
 var log = logrus.WithFields(logrus.Fields{"gopher": "discord_bot"})
 
 func getTextWithCtx(i *discordgo.InteractionCreate, key string) string {
-	// Try interaction locale first
+	// Guild override wins over user locale
+	if st := resolveSettings(i); st.Locale != "" {
+		return i18n_pkg.T(st.Locale, key)
+	}
+	// Try interaction locale next
 	if i != nil && i.Interaction != nil {
 		var raw string
 		if i.Interaction.Locale != "" {
@@ -59,39 +67,85 @@ func GoDiscordBot(ctx context.Context, wg *sync.WaitGroup) {
 		log.Fatal("Error creating Discord session: ", err)
 	}
 
-	client := storyclient.NewClient()
+	stateSession = dg
 
 	dg.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		// every lookup of this interaction goes to the network it is about
+		client := interactionClient(i)
 		if i.Type == discordgo.InteractionApplicationCommand {
 			data := i.ApplicationCommandData()
 			name := data.Name
-			if len(data.Options) == 0 {
+			// subcommand groups, context menus and batch input carry no single string param
+			if name == "settings" {
+				handleSettings(s, i)
+				return
+			}
+			if name == "wallet" {
+				handleWallet(s, i)
+				return
+			}
+			if name == compareCommand {
+				handleCompare(s, i, client)
+				return
+			}
+			if name == trendingCommand {
+				handleTrending(s, i, client)
+				return
+			}
+			if name == latestCommand {
+				handleLatest(s, i, client)
+				return
+			}
+			if name == collectionsCommand {
+				handleCollections(s, i, client)
+				return
+			}
+			if name == licenseBatchCommand {
+				handleLicenseBatch(s, i, client)
+				return
+			}
+			if data.CommandType == discordgo.MessageApplicationCommand && name == checkLicensesCommand {
+				handleCheckLicensesMessage(s, i, client)
+				return
+			}
+			if data.CommandType == discordgo.UserApplicationCommand && name == showPortfolioCommand {
+				handleShowPortfolio(s, i, client)
+				return
+			}
+			// options arrive in no guaranteed order, so the lookup target is read by name
+			param, ok := stringOption(data.Options, "ip_id", "address")
+			if !ok {
 				_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionResponseData{Content: getTextWithCtx(i, "missing_param")},
 				})
 				return
 			}
-			param := data.Options[0].StringValue()
 
 			// Dispatch commands
 			switch name {
 			case "license":
 				handleLicense(s, i, client, param)
 			case "license_terms":
-				handleLicenseTerms(s, i, client, param)
+				handleLicenseView(s, i, client, param, viewTerms)
 			case "license_infringement":
-				handleLicenseInfringement(s, i, client, param)
+				handleLicenseView(s, i, client, param, viewInfringement)
 			case "license_moderation":
-				handleLicenseModeration(s, i, client, param)
+				handleLicenseView(s, i, client, param, viewModeration)
 			case "license_mint":
-				handleLicenseMint(s, i, client, param)
+				handleLicenseView(s, i, client, param, viewMint)
 			case "license_collection":
-				handleLicenseCollection(s, i, client, param)
+				handleLicenseView(s, i, client, param, viewCollection)
+			case "license_history":
+				handleLicenseHistory(s, i, client, param)
+			case "risk":
+				handleRisk(s, i, client, param)
 			case "collection":
 				handleCollection(s, i, client, param)
 			case "collection_disputes":
 				handleCollectionDisputes(s, i, client, param)
+			case "collection_stats":
+				handleCollectionStats(s, i, client, param)
 			default:
 				_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -101,6 +155,11 @@ func GoDiscordBot(ctx context.Context, wg *sync.WaitGroup) {
 			return
 		}
 
+		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			handleAutocomplete(s, i, client)
+			return
+		}
+
 		// component interaction (button clicks)
 		if i.Type == discordgo.InteractionMessageComponent {
 			handleComponentInteraction(s, i, client)
@@ -108,11 +167,22 @@ func GoDiscordBot(ctx context.Context, wg *sync.WaitGroup) {
 		}
 	})
 
+	// passive previews need the privileged message content intent
+	if config.AUTO_PREVIEW_ENABLED {
+		dg.Identify.Intents |= discordgo.IntentsGuildMessages | discordgo.IntentMessageContent
+		loadPreviewChannels()
+		dg.AddHandler(handleMessageCreate())
+	}
+
 	if err := dg.Open(); err != nil {
 		log.Fatal("Error opening connection: ", err)
 	}
 
 	createCommands(dg)
+	go runExpirySweeper(ctx, dg)
+	go runLatestFeed(ctx, dg)
+	go runDescendantSnapshots(ctx)
+	go runLicenseSnapshots(ctx)
 	log.Info("Discord bot is running...")
 	<-ctx.Done()
 	dg.Close()
@@ -121,14 +191,39 @@ func GoDiscordBot(ctx context.Context, wg *sync.WaitGroup) {
 
 func createCommands(dg *discordgo.Session) {
 	cmds := []*discordgo.ApplicationCommand{
-		{Name: "license", Description: "Check license by IP ID", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "Intellectual Property ID", Required: true}}},
-		{Name: "license_terms", Description: "Show license terms", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true}}},
-		{Name: "license_infringement", Description: "Show infringement status", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true}}},
-		{Name: "license_moderation", Description: "Show moderation safety", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true}}},
-		{Name: "license_mint", Description: "Show mint info", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true}}},
-		{Name: "license_collection", Description: "Show collection/contract info for license", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true}}},
-		{Name: "collection", Description: "Get collection info by contract address", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "address", Description: "Contract address", Required: true}}},
-		{Name: "collection_disputes", Description: "Get collection disputes counters", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "address", Description: "Contract address", Required: true}}},
+		{Name: "license", Description: "Check license by IP ID", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "Intellectual Property ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "license_terms", Description: "Show license terms", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "license_infringement", Description: "Show infringement status", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "license_moderation", Description: "Show moderation safety", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "license_mint", Description: "Show mint info", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "license_collection", Description: "Show collection/contract info for license", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "license_history", Description: "Show observed changes of license terms", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "risk", Description: "Assess the risk of using an IP asset", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "ip_id", Description: "IP ID", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "collection", Description: "Get collection info by contract address", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "address", Description: "Contract address", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "collection_disputes", Description: "Get collection disputes counters", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "address", Description: "Contract address", Required: true, Autocomplete: true}, privateOption}},
+		{Name: "collection_stats", Description: "Collection dispute counters, plus license and moderation stats of its recently minted assets", Options: []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "address", Description: "Contract address", Required: true, Autocomplete: true}, privateOption}},
+		collectionsCommandDef(),
+		latestCommandDef(),
+		trendingCommandDef(),
+		licenseBatchCommandDef(),
+		compareCommandDef(),
+		settingsCommand(),
+		checkLicensesMessageCommand(),
+		walletCommand(),
+		showPortfolioUserCommand(),
+	}
+	// lookup commands, recognizable by their private option, can pick a network;
+	// their handlers read options by name, so the extra option never shadows
+	// the lookup target
+	if opt := networkOption(); opt != nil {
+		for _, c := range cmds {
+			for _, o := range c.Options {
+				if o == privateOption {
+					c.Options = append(c.Options, opt)
+					break
+				}
+			}
+		}
 	}
 
 	// desired command names set
@@ -161,8 +256,52 @@ func createCommands(dg *discordgo.Session) {
 }
 
 // --- helpers ---
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	} else if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// privateOption lets the invoker override the guild's ephemeral default.
+var privateOption = &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionBoolean, Name: "private", Description: "Only show the result to you"}
+
+// stringOption returns the value of the first string option named one of names.
+func stringOption(opts []*discordgo.ApplicationCommandInteractionDataOption, names ...string) (string, bool) {
+	for _, o := range opts {
+		if o.Type != discordgo.ApplicationCommandOptionString {
+			continue
+		}
+		for _, n := range names {
+			if o.Name == n {
+				return o.StringValue(), true
+			}
+		}
+	}
+	return "", false
+}
+
+// isEphemeral reports whether results should be visible only to the invoker:
+// the command's private option when given, else the guild default.
+func isEphemeral(i *discordgo.InteractionCreate) bool {
+	if i.Type == discordgo.InteractionApplicationCommand {
+		for _, o := range i.ApplicationCommandData().Options {
+			if o.Name == privateOption.Name && o.Type == discordgo.ApplicationCommandOptionBoolean {
+				return o.BoolValue()
+			}
+		}
+	}
+	return resolveSettings(i).Ephemeral
+}
+
 func respondDeferred(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredChannelMessageWithSource})
+	resp := &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredChannelMessageWithSource}
+	if isEphemeral(i) {
+		resp.Data = &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral}
+	}
+	err := s.InteractionRespond(i.Interaction, resp)
 	if err != nil {
 		// ignore "already been acknowledged" errors (40060) which can happen
 		// when a component interaction was already deferred by the caller.
@@ -174,29 +313,59 @@ func respondDeferred(s *discordgo.Session, i *discordgo.InteractionCreate) error
 	return nil
 }
 
-func followupEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
-	// apply unified footer
-	if embed != nil {
-		embed.Footer = &discordgo.MessageEmbedFooter{Text: "🧩 Powered by Endorphine Stake"}
+// applyEmbedDefaults sets the unified footer and caps fields at Discord's limit of 25.
+func applyEmbedDefaults(i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	if embed == nil {
+		return
+	}
+	footer := resolveSettings(i).FooterText
+	// results from anything but the default network are labeled as such
+	if n := interactionNetwork(i); n != network.Networks().Default() {
+		footer = n.Label + " · " + footer
 	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
 	if len(embed.Fields) > 25 {
 		embed.Fields = embed.Fields[:25]
 	}
-	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Embeds: []*discordgo.MessageEmbed{embed}}); err != nil {
+}
+
+func followupEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	applyEmbedDefaults(i, embed)
+	params := &discordgo.WebhookParams{Embeds: []*discordgo.MessageEmbed{embed}}
+	if isEphemeral(i) {
+		params.Flags = discordgo.MessageFlagsEphemeral
+	}
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, params); err != nil {
 		logrus.Error("Failed to send followup embed: ", err)
 	}
 }
 
-func followupEmbedWithComponents(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) (*discordgo.Message, error) {
-	// apply unified footer
-	if embed != nil {
-		embed.Footer = &discordgo.MessageEmbedFooter{Text: "🧩 Powered by Endorphine Stake"}
+// followupEmbeds sends several embeds in one message (Discord allows up to 10).
+func followupEmbeds(s *discordgo.Session, i *discordgo.InteractionCreate, embeds []*discordgo.MessageEmbed) {
+	for _, embed := range embeds {
+		applyEmbedDefaults(i, embed)
 	}
-	if len(embed.Fields) > 25 {
-		embed.Fields = embed.Fields[:25]
+	params := &discordgo.WebhookParams{Embeds: embeds}
+	if isEphemeral(i) {
+		params.Flags = discordgo.MessageFlagsEphemeral
+		params.Components = validComponents(withShareRow(i, embeds, nil))
 	}
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, params); err != nil {
+		logrus.Error("Failed to send followup embeds: ", err)
+	}
+}
+
+// followupEmbedWithComponents sends a lookup result. Unlike followupEmbed it
+// offers a Share button when the result is ephemeral.
+func followupEmbedWithComponents(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) (*discordgo.Message, error) {
+	applyEmbedDefaults(i, embed)
 	params := &discordgo.WebhookParams{Embeds: []*discordgo.MessageEmbed{embed}}
-	if len(components) > 0 {
+	if isEphemeral(i) {
+		params.Flags = discordgo.MessageFlagsEphemeral
+		// results only the invoker sees can be re-posted publicly
+		components = withShareRow(i, params.Embeds, components)
+	}
+	if components = validComponents(components); len(components) > 0 {
 		params.Components = components
 	}
 	msg, err := s.FollowupMessageCreate(i.Interaction, true, params)
@@ -207,24 +376,43 @@ func followupEmbedWithComponents(s *discordgo.Session, i *discordgo.InteractionC
 	return msg, nil
 }
 
+// disableComponentsCopy disables buttons and select menus. Components decoded
+// from a fetched message are pointers, those we built are values.
 func disableComponentsCopy(components []discordgo.MessageComponent) []discordgo.MessageComponent {
 	var out []discordgo.MessageComponent
 	for _, c := range components {
-		if ar, ok := c.(discordgo.ActionsRow); ok {
-			var rowComponents []discordgo.MessageComponent
-			for _, rc := range ar.Components {
-				if b, ok := rc.(discordgo.Button); ok {
-					nb := b
-					nb.Disabled = true
-					rowComponents = append(rowComponents, nb)
-				} else {
-					rowComponents = append(rowComponents, rc)
-				}
-			}
-			out = append(out, discordgo.ActionsRow{Components: rowComponents})
-		} else {
+		var ar discordgo.ActionsRow
+		switch row := c.(type) {
+		case discordgo.ActionsRow:
+			ar = row
+		case *discordgo.ActionsRow:
+			ar = *row
+		default:
 			out = append(out, c)
+			continue
 		}
+		var rowComponents []discordgo.MessageComponent
+		for _, rc := range ar.Components {
+			switch c := rc.(type) {
+			case discordgo.Button:
+				c.Disabled = true
+				rowComponents = append(rowComponents, c)
+			case *discordgo.Button:
+				nb := *c
+				nb.Disabled = true
+				rowComponents = append(rowComponents, nb)
+			case discordgo.SelectMenu:
+				c.Disabled = true
+				rowComponents = append(rowComponents, c)
+			case *discordgo.SelectMenu:
+				nm := *c
+				nm.Disabled = true
+				rowComponents = append(rowComponents, nm)
+			default:
+				rowComponents = append(rowComponents, rc)
+			}
+		}
+		out = append(out, discordgo.ActionsRow{Components: rowComponents})
 	}
 	return out
 }
@@ -235,17 +423,9 @@ func handleLicense(s *discordgo.Session, i *discordgo.InteractionCreate, client
 		logrus.Error("defer failed: ", err)
 		return
 	}
-	asset, err := client.GetAssetByID(ipId)
+	asset, err := fetchAsset(client, ipId)
 	if err != nil {
-		// Friendly warn for invalid input formats
-		if ae, ok := err.(*storyclient.APIError); ok {
-			low := strings.ToLower(ae.Detail + " " + ae.Title)
-			if strings.Contains(low, "invalid ip asset id") || strings.Contains(low, "invalid ip id") {
-				followupEmbed(s, i, &discordgo.MessageEmbed{Title: "", Description: getTextWithCtx(i, "invalid_ip_id"), Color: 0xFFFF00})
-				return
-			}
-		}
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		followupAssetError(s, i, err)
 		return
 	}
 	if asset == nil {
@@ -253,6 +433,31 @@ func handleLicense(s *discordgo.Session, i *discordgo.InteractionCreate, client
 		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "License", Description: getText("not_found"), Color: 0xFFFF00})
 		return
 	}
+	recordLookup(i, models.LookupKindIP, ipId, asset.Title)
+	embed, components := renderLicenseCard(i, client, asset, ipId, viewOverview, 0)
+	msg, err := followupEmbedWithComponents(s, i, embed, components)
+	if err == nil && msg != nil && len(msg.Components) > 0 {
+		// expire after configured timeout; the sweeper deletes or disables it
+		scheduleExpiry(i, msg, models.ExpiryKindLicense, ipId)
+	}
+}
+
+// followupAssetError reports a failed asset fetch, with a friendly warning for
+// malformed IP IDs.
+func followupAssetError(s *discordgo.Session, i *discordgo.InteractionCreate, err error) {
+	if ae, ok := err.(*storyclient.APIError); ok {
+		low := strings.ToLower(ae.Detail + " " + ae.Title)
+		if strings.Contains(low, "invalid ip asset id") || strings.Contains(low, "invalid ip id") {
+			followupEmbed(s, i, &discordgo.MessageEmbed{Title: "", Description: getTextWithCtx(i, "invalid_ip_id"), Color: 0xFFFF00})
+			return
+		}
+	}
+	followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+}
+
+// buildLicenseEmbed renders the license card embed and resolves the media URL
+// for the Play button without exposing it in the embed.
+func buildLicenseEmbed(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, string) {
 	title := ipId
 	desc := ""
 	if asset.Title != "" {
@@ -260,9 +465,8 @@ func handleLicense(s *discordgo.Session, i *discordgo.InteractionCreate, client
 	}
 	desc = asset.Description
 
-	embed := &discordgo.MessageEmbed{Title: title, Description: desc, Color: 0x00FF00}
+	embed := &discordgo.MessageEmbed{Title: title, Description: desc, Color: accentColor(i, 0x00FF00), URL: explorerLinks(i).IP(ipId)}
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_id"), Value: ipId, Inline: false})
-	var components []discordgo.MessageComponent
 	if asset.OwnerAddress != "" {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_owner"), Value: asset.OwnerAddress, Inline: true})
 	}
@@ -319,41 +523,15 @@ func handleLicense(s *discordgo.Session, i *discordgo.InteractionCreate, client
 			}
 		}
 	}
-	// add action buttons (scoped to user) - Terms | Infringement | Moderation | Mint | Collection
-	// include user id in custom_id to restrict button usage
-	uid := ""
-	if i.Member != nil && i.Member.User != nil {
-		uid = i.Member.User.ID
-	} else if i.User != nil {
-		uid = i.User.ID
-	}
-	if uid != "" {
-		locale := i18n_pkg.DetectLocale(configs.GetEnvConfig().LOCALE)
-		// choose collection button target: if we have contract address, call collection by address, else fallback to license collection
-		collCID := fmt.Sprintf("lic:coll:%s:%s", ipId, uid)
-		if asset.Contract != nil && asset.Contract.Address != "" {
-			collCID = fmt.Sprintf("col:show:%s:%s", asset.Contract.Address, uid)
+	if asset.Moderation != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "title_moderation"), Value: moderationSummary(evaluateModeration(i, asset.Moderation)), Inline: true})
+	}
+	// keep sensitive media out of regular channels
+	if hide, note := mediaGate(i, asset); note != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_media"), Value: note, Inline: false})
+		if hide {
+			playLink = ""
 		}
-		// primary row: up to 5 action buttons
-		primaryRow := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_terms"), Style: discordgo.PrimaryButton, CustomID: fmt.Sprintf("lic:terms:%s:%s", ipId, uid)},
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_infringement"), Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("lic:infr:%s:%s", ipId, uid)},
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_moderation"), Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("lic:mod:%s:%s", ipId, uid)},
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_mint"), Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("lic:mint:%s:%s", ipId, uid)},
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_collection"), Style: discordgo.SecondaryButton, CustomID: collCID},
-		}}
-		components = append(components, primaryRow)
-	}
-
-	// if we have a play link (Image or Animation), add a separate link button row
-	if playLink != "" {
-		locale := i18n_pkg.DetectLocale(configs.GetEnvConfig().LOCALE)
-		playRow := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_play"), Style: discordgo.LinkButton, URL: playLink},
-		}}
-		// prepend playRow so it appears above other action rows
-		newComps := append([]discordgo.MessageComponent{playRow}, components...)
-		components = newComps
 	}
 	if asset.CreatedAt != nil {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_created"), Value: asset.CreatedAt.UTC().Format("2006-01-02"), Inline: true})
@@ -361,82 +539,7 @@ func handleLicense(s *discordgo.Session, i *discordgo.InteractionCreate, client
 	if asset.UpdatedAt != nil {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_updated"), Value: asset.UpdatedAt.UTC().Format("2006-01-02"), Inline: true})
 	}
-	if len(components) > 0 {
-		msg, err := followupEmbedWithComponents(s, i, embed, components)
-		if err == nil && msg != nil {
-			// schedule deletion after configured timeout; fallback to disabling if delete fails
-			go func(channelID, messageID string, comps []discordgo.MessageComponent) {
-				t := time.Duration(configs.GetEnvConfig().STORY_BUTTON_TIMEOUT_SEC) * time.Second
-				time.Sleep(t)
-				// try delete
-				if derr := s.ChannelMessageDelete(channelID, messageID); derr != nil {
-					logrus.Debugf("failed to delete message: %v, falling back to disabling components", derr)
-					disabled := disableComponentsCopy(comps)
-					_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
-						ID:         messageID,
-						Channel:    channelID,
-						Components: &disabled,
-					})
-					if err != nil {
-						logrus.Debugf("failed to disable components: %v", err)
-					}
-				}
-			}(msg.ChannelID, msg.ID, components)
-		}
-	} else {
-		followupEmbed(s, i, embed)
-	}
-}
-
-func handleLicenseTerms(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
-		return
-	}
-	t, err := client.GetAssetTerms(ipId)
-	if err != nil {
-		if ae, ok := err.(*storyclient.APIError); ok {
-			low := strings.ToLower(ae.Detail + " " + ae.Title)
-			if strings.Contains(low, "invalid ip asset id") || strings.Contains(low, "invalid ip id") {
-				followupEmbed(s, i, &discordgo.MessageEmbed{Title: "", Description: getText("invalid_ip_id"), Color: 0xFFFF00})
-				return
-			}
-		}
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
-		return
-	}
-	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_terms"), Color: 0x00AAFF}
-	if t == nil {
-		// Yellow warn if terms not found (likely random/invalid id)
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_terms"), Description: getText("no_terms"), Color: 0xFFFF00})
-		return
-	}
-	// Template fields split per spec
-	if t.LicenseTemplateId != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_template"), Value: t.LicenseTemplateId, Inline: false})
-	}
-	if t.TemplateName != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_template_name"), Value: strings.ToUpper(t.TemplateName), Inline: false})
-	}
-	if t.TemplateMetadataUri != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_template_url"), Value: t.TemplateMetadataUri, Inline: false})
-	}
-	if t.Terms != nil {
-		yes := map[bool]string{true: "✅", false: "❌"}
-		// Render with License first line, then a clean list
-		lines := []string{
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_id"), ipId),
-			"",
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_transferable"), yes[t.Terms.Transferable]),
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_commercial_use"), yes[t.Terms.CommercialUse]),
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_derivatives_allowed"), yes[t.Terms.DerivativesAllowed]),
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_derivatives_approval"), yes[t.Terms.DerivativesApproval]),
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_commercial_rev_share"), formatRevSharePercent(t.Terms.CommercialRevShare)),
-			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_attribution"), yes[t.Terms.AttributionRequired]),
-		}
-		embed.Description = strings.Join(lines, "\n")
-	}
-	followupEmbed(s, i, embed)
+	return embed, playLink
 }
 
 // formatRevSharePercent converts raw commercialRevShare integer into a human-friendly percent.
@@ -452,381 +555,143 @@ func formatRevSharePercent(n int) string {
 	return fmt.Sprintf("%.2f%%", pct)
 }
 
-func handleLicenseInfringement(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
-		return
-	}
-	arr, err := client.GetAssetInfringement(ipId)
-	if err != nil {
-		if ae, ok := err.(*storyclient.APIError); ok {
-			low := strings.ToLower(ae.Detail + " " + ae.Title)
-			if strings.Contains(low, "invalid ip asset id") || strings.Contains(low, "invalid ip id") {
-				followupEmbed(s, i, &discordgo.MessageEmbed{Title: "", Description: getTextWithCtx(i, "invalid_ip_id"), Color: 0xFFFF00})
-				return
-			}
-		}
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
-		return
-	}
-	if len(arr) == 0 {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Infringement Status", Description: getText("no_checks"), Color: 0xFFFF00})
-		return
-	}
-	status := "✅ Not infringing"
-	color := 0x00FF00
-	for _, it := range arr {
-		if it.IsInfringing {
-			status = "❌ Infringing"
-			color = 0xFF0000
-			break
-		}
-		if strings.ToLower(it.Status) != "succeeded" {
-			status = "⚠️ Potential issue"
-			color = 0xFFAA00
-		}
-	}
-	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_infringement"), ipId), Color: color, Description: status}
-	latest := arr[0]
-	if latest.ProviderName != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_provider"), Value: latest.ProviderName, Inline: true})
-	}
-	if latest.ResponseTime != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_checked"), Value: latest.ResponseTime.UTC().Format("2006-01-02 15:04 UTC"), Inline: true})
-	}
-	followupEmbed(s, i, embed)
-}
-
-func handleLicenseModeration(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
-		return
-	}
-	m, err := client.GetAssetModeration(ipId)
-	if err != nil {
-		if ae, ok := err.(*storyclient.APIError); ok {
-			low := strings.ToLower(ae.Detail + " " + ae.Title)
-			if strings.Contains(low, "invalid ip asset id") || strings.Contains(low, "invalid ip id") {
-				followupEmbed(s, i, &discordgo.MessageEmbed{Title: "", Description: getTextWithCtx(i, "invalid_ip_id"), Color: 0xFFFF00})
-				return
-			}
-		}
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
-		return
-	}
-	if m == nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Moderation", Description: getText("no_moderation"), Color: 0xFFFF00})
-		return
-	}
-	// evaluate
-	eval := evaluateModeration(m)
-	color := 0x00FF00
-	if eval == "Unsafe" {
-		color = 0xFF0000
-	} else if eval == "Review" {
-		color = 0xFFAA00
-	}
-	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_moderation"), ipId), Color: color, Description: eval}
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "adult"), Value: m.Adult, Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "spoof"), Value: m.Spoof, Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "medical"), Value: m.Medical, Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "violence"), Value: m.Violence, Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "racy"), Value: m.Racy, Inline: true})
-	followupEmbed(s, i, embed)
+// evaluateModeration applies the guild's moderation policy to an asset's status.
+func evaluateModeration(i *discordgo.InteractionCreate, m *dto.ModerationStatus) moderation.Result {
+	return resolveSettings(i).ModerationPolicy.Evaluate(map[moderation.Category]string{
+		moderation.Adult:    m.Adult,
+		moderation.Spoof:    m.Spoof,
+		moderation.Medical:  m.Medical,
+		moderation.Violence: m.Violence,
+		moderation.Racy:     m.Racy,
+	})
 }
 
-func evaluateModeration(m *dto.ModerationStatus) string {
-	// Note: storyclient.ModerationStatus type is defined in dto; use string checks
-	// If any field LIKELY or VERY_LIKELY -> Unsafe
-	// Else if any POSSIBLE -> Review
-	// Else Safe
-	anyPossible := false
-	for _, v := range []string{m.Adult, m.Spoof, m.Medical, m.Violence, m.Racy} {
-		up := strings.ToUpper(v)
-		if strings.Contains(up, "VERY_LIKELY") || strings.Contains(up, "LIKELY") {
-			return "Unsafe"
-		}
-		if strings.Contains(up, "POSSIBLE") || strings.Contains(up, "POSSIBLY") {
-			anyPossible = true
-		}
+// moderationSummary is the verdict followed by the categories that caused it.
+func moderationSummary(res moderation.Result) string {
+	if len(res.Triggers) == 0 {
+		return string(res.Verdict)
 	}
-	if anyPossible {
-		return "Review"
-	}
-	return "Safe"
+	return fmt.Sprintf("%s (%s)", res.Verdict, strings.Join(res.Categories(), ", "))
 }
 
-func handleLicenseMint(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
-		return
-	}
-	m, err := client.GetAssetMint(ipId)
+// handleComponentInteraction decodes the signed custom_id and re-renders the
+// card it belongs to in place with the chosen view or tab.
+func handleComponentInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	p, err := decodeComponentID(i.MessageComponentData().CustomID)
 	if err != nil {
-		if ae, ok := err.(*storyclient.APIError); ok {
-			low := strings.ToLower(ae.Detail + " " + ae.Title)
-			if strings.Contains(low, "invalid ip asset id") || strings.Contains(low, "invalid ip id") {
-				followupEmbed(s, i, &discordgo.MessageEmbed{Title: "", Description: getTextWithCtx(i, "invalid_ip_id"), Color: 0xFFFF00})
-				return
-			}
+		key := "invalid_component"
+		if errors.Is(err, customid.ErrExpired) {
+			key = "component_expired"
 		}
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		respondEphemeral(s, i, getTextWithCtx(i, key))
 		return
 	}
-	if m == nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Mint Info", Description: getText("no_mint"), Color: 0xFFFF00})
+
+	// restrict buttons to owner
+	if p.Owner != "" && interactionUserID(i) != p.Owner {
+		respondEphemeral(s, i, getTextWithCtx(i, "unauth_button"))
 		return
 	}
-	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_mint"), ipId), Color: 0x0099FF}
-	var comps []discordgo.MessageComponent
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "mint_address"), Value: m.MintAddress, Inline: false})
-	if m.BlockNumber != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "block_number"), Value: m.BlockNumber.String(), Inline: true})
-	}
-	if m.Timestamp != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "mint_timestamp"), Value: m.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"), Inline: true})
-	}
-	if m.TransactionHash != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "transaction"), Value: m.TransactionHash, Inline: false})
-		// storyscan button
-		txUrl := fmt.Sprintf("https://www.storyscan.io/tx/%s", m.TransactionHash)
-		locale := i18n_pkg.DetectLocale(configs.GetEnvConfig().LOCALE)
-		comps = append(comps, discordgo.ActionsRow{Components: []discordgo.MessageComponent{discordgo.Button{Label: i18n_pkg.T(locale, "btn_storyscan"), Style: discordgo.LinkButton, URL: txUrl}}})
-	}
-	if m.OwnerAddress != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_owner"), Value: m.OwnerAddress, Inline: true})
-	}
-	if m.LastUpdatedAt != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "last_updated_metadata"), Value: m.LastUpdatedAt.UTC().Format(time.RFC3339), Inline: true})
-	}
-	if m.TimeLastUpdated != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "last_updated_system"), Value: m.TimeLastUpdated.UTC().Format(time.RFC3339), Inline: true})
-	}
-	if len(comps) > 0 {
-		followupEmbedWithComponents(s, i, embed, comps)
-	} else {
-		followupEmbed(s, i, embed)
-	}
-}
 
-func handleLicenseCollection(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
+	// sharing posts a new public message instead of updating this one
+	if p.Kind == customid.KindResult && p.Action == customid.ActionShare {
+		handleShareResult(s, i, p.Target)
 		return
 	}
-	asset, err := client.GetAssetByID(ipId)
-	if err != nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+
+	// exports are sent as a separate ephemeral attachment
+	if p.Action == customid.ActionExport {
+		handleExport(s, i, client, p)
 		return
 	}
-	if asset == nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Collection", Description: getText("no_collection"), Color: 0xFFFF00})
+
+	// every component re-renders the message it is attached to
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
+		logrus.Error("defer update failed: ", err)
 		return
 	}
-	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_collection"), Color: 0x00CC99}
-	// Show License ID first
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_id"), Value: ipId, Inline: false})
-	// prefer nested nftMetadata.contract/collection per openapi; fallback to top-level fields
-	var contract *dto.ContractInfo
-	var collection *dto.CollectionInfo
-	if asset.NFTMetadata != nil {
-		if asset.NFTMetadata.Contract != nil {
-			contract = asset.NFTMetadata.Contract
+
+	var (
+		embed      *discordgo.MessageEmbed
+		components []discordgo.MessageComponent
+	)
+	id := p.Target
+	switch p.Kind {
+	case customid.KindLicense:
+		key, ok := licenseActionViews[p.Action]
+		if p.Action == customid.ActionView {
+			key, ok = viewOverview, true
+			if values := i.MessageComponentData().Values; len(values) > 0 {
+				key = values[0]
+			}
 		}
-		if asset.NFTMetadata.Collection != nil {
-			collection = asset.NFTMetadata.Collection
+		if !ok {
+			followupComponentError(s, i, getTextWithCtx(i, "unknown_action"))
+			return
 		}
-	}
-	if contract == nil {
-		contract = asset.Contract
-	}
-	if collection == nil {
-		collection = asset.Collection
-	}
-	if contract != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "contract_name"), Value: contract.Name, Inline: true})
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "symbol"), Value: contract.Symbol, Inline: true})
-		if contract.Address != "" {
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "contract_address"), Value: contract.Address, Inline: false})
+		asset, err := fetchAsset(client, id)
+		if err != nil {
+			followupComponentError(s, i, err.Error())
+			return
 		}
-		if contract.TotalSupply != nil {
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "total_supply"), Value: contract.TotalSupply.String() + " NFTs", Inline: true})
+		if asset == nil {
+			followupComponentError(s, i, getTextWithCtx(i, "not_found"))
+			return
 		}
-	}
-	// Collection name can be absent (one-off NFT). Always show the field per spec.
-	if collection != nil && collection.Name != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "collection_name"), Value: collection.Name, Inline: false})
-	} else {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "collection_name"), Value: "❌", Inline: false})
-	}
-	// add button to open full collection view, if we know the contract address and have user id
-	uid := ""
-	if i.Member != nil && i.Member.User != nil {
-		uid = i.Member.User.ID
-	} else if i.User != nil {
-		uid = i.User.ID
-	}
-	if uid != "" && contract != nil && contract.Address != "" {
-		locale := i18n_pkg.DetectLocale(configs.GetEnvConfig().LOCALE)
-		actionRow := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_view_collection"), Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("col:show:%s:%s", contract.Address, uid)},
-		}}
-		_, _ = followupEmbedWithComponents(s, i, embed, []discordgo.MessageComponent{actionRow})
-		return
-	}
-	followupEmbed(s, i, embed)
-}
-
-func handleCollection(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, addr string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
-		return
-	}
-	meta, err := client.GetCollectionByAddress(addr)
-	if err != nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
-		return
-	}
-	if meta == nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Collection", Description: getText("not_found"), Color: 0xFFFF00})
-		return
-	}
-	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s %s", getTextWithCtx(i, "title_collection"), addr), Color: 0x0055FF}
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "name"), Value: meta.Name, Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "symbol"), Value: meta.Symbol, Inline: true})
-	if meta.TotalSupply != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "total_supply"), Value: meta.TotalSupply.String(), Inline: true})
-	}
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "token_type"), Value: meta.TokenType, Inline: true})
-	if meta.CreatedAt != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_created"), Value: meta.CreatedAt.UTC().Format("2006-01-02"), Inline: true})
-	}
-	if meta.UpdatedAt != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_updated"), Value: meta.UpdatedAt.UTC().Format("2006-01-02"), Inline: true})
-	}
-	// add action buttons (Disputes) - include user id if present
-	uid := ""
-	if i.Member != nil && i.Member.User != nil {
-		uid = i.Member.User.ID
-	} else if i.User != nil {
-		uid = i.User.ID
-	}
-	if uid != "" {
-		locale := i18n_pkg.DetectLocale(configs.GetEnvConfig().LOCALE)
-		actionRow := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
-			discordgo.Button{Label: i18n_pkg.T(locale, "btn_disputes"), Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("col:disputes:%s:%s", addr, uid)},
-		}}
-		_, _ = followupEmbedWithComponents(s, i, embed, []discordgo.MessageComponent{actionRow})
+		embed, components = renderLicenseCard(i, client, asset, id, key, int(p.Arg))
+	case customid.KindCollection:
+		if p.Action != customid.ActionShow && p.Action != customid.ActionDisputes {
+			followupComponentError(s, i, getTextWithCtx(i, "unknown_action"))
+			return
+		}
+		embed, components, err = renderCollectionCard(i, client, id, p.Action)
+		if err != nil {
+			followupComponentError(s, i, err.Error())
+			return
+		}
+	case customid.KindCollectionList:
+		f, perr := parseCollectionFilter(id)
+		if perr != nil {
+			followupComponentError(s, i, getTextWithCtx(i, "invalid_component"))
+			return
+		}
+		embed, components, err = renderCollectionList(i, client, f, int(p.Arg))
+		if err != nil {
+			followupComponentError(s, i, err.Error())
+			return
+		}
+	case customid.KindTrending:
+		if p.Action != customid.ActionShow {
+			followupComponentError(s, i, getTextWithCtx(i, "unknown_action"))
+			return
+		}
+		embed, components, err = renderTrending(i, client, id, int(p.Arg))
+		if err != nil {
+			followupComponentError(s, i, err.Error())
+			return
+		}
+	default:
+		followupComponentError(s, i, getTextWithCtx(i, "unknown_component"))
 		return
 	}
-	followupEmbed(s, i, embed)
-}
 
-func handleCollectionDisputes(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, addr string) {
-	if err := respondDeferred(s, i); err != nil {
-		logrus.Error(err)
-		return
+	applyEmbedDefaults(i, embed)
+	if i.Message != nil && i.Message.Flags&discordgo.MessageFlagsEphemeral != 0 {
+		components = withShareRow(i, []*discordgo.MessageEmbed{embed}, components)
 	}
-	item, err := client.GetCollectionDisputes(addr)
+	components = validComponents(components)
+	msg, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Embeds: &[]*discordgo.MessageEmbed{embed}, Components: &components})
 	if err != nil {
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		logrus.Error("failed to update card: ", err)
 		return
 	}
-	if item == nil {
-		// Yellow warn for not found collection address
-		followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_disputes"), Description: getText("not_found"), Color: 0xFFFF00})
-		return
+	// a refreshed card starts a new expiry period for whoever clicked Refresh
+	if p.Action == customid.ActionRefresh {
+		scheduleExpiry(i, msg, models.ExpiryKindLicense, id)
 	}
-	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_disputes"), addr), Color: 0x00AA00}
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Raised", Value: fmt.Sprintf("%d", item.RaisedDisputeCount), Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Resolved", Value: fmt.Sprintf("%d", item.ResolvedDisputeCount), Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Cancelled", Value: fmt.Sprintf("%d", item.CancelledDisputeCount), Inline: true})
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Judged", Value: fmt.Sprintf("%d", item.JudgedDisputeCount), Inline: true})
-	followupEmbed(s, i, embed)
 }
 
-// handleComponentInteraction parses button custom_id and routes to command handlers.
-// custom_id format: "lic:<action>:<ipId>" where action in terms|infringement|moderation|mint|collection
-func handleComponentInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
-	// Acknowledge interaction quickly to avoid 'Interaction Failed' (must respond within 3s)
-	if err := respondDeferred(s, i); err != nil {
-		// Log prominently and try a fallback immediate ephemeral response so the client
-		// doesn't stay in "thinking" state.
-		logrus.Warnf("failed to defer component interaction: %v; attempting fallback ACK", err)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{Content: "", Flags: 1 << 6},
-		})
-	}
-	cid := i.MessageComponentData().CustomID
-	parts := strings.Split(cid, ":")
-	if len(parts) < 4 {
-		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: getTextWithCtx(i, "invalid_component")})
-		return
-	}
-	kind := parts[0]
-	action := parts[1]
-	// support both 4-part and 5-part formats
-	var id, ownerId string
-	if len(parts) == 4 {
-		id = parts[2]
-		ownerId = parts[3]
-	} else if len(parts) >= 5 {
-		// legacy formats may include an extra part; ignore mode
-		id = parts[3]
-		ownerId = parts[4]
-	}
-
-	// disable buttons immediately to avoid double clicks
-	if i.Message != nil && len(i.Message.Components) > 0 {
-		disabled := disableComponentsCopy(i.Message.Components)
-		_, _ = s.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: i.Message.ID, Channel: i.Message.ChannelID, Components: &disabled})
-	}
-
-	// restrict buttons to owner
-	clicker := ""
-	if i.Member != nil && i.Member.User != nil {
-		clicker = i.Member.User.ID
-	} else if i.User != nil {
-		clicker = i.User.ID
-	}
-	if ownerId != "" && clicker != ownerId {
-		// Send an ephemeral followup message to notify unauthorized user.
-		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: getTextWithCtx(i, "unauth_button"),
-			Flags:   1 << 6,
-		})
-		return
-	}
-	// view toggle removed; any 'view:*' components are unknown now
-	switch kind {
-	case "lic":
-		switch action {
-		case "terms":
-			handleLicenseTerms(s, i, client, id)
-		case "infr":
-			handleLicenseInfringement(s, i, client, id)
-		case "mod":
-			handleLicenseModeration(s, i, client, id)
-		case "mint":
-			handleLicenseMint(s, i, client, id)
-		case "coll":
-			handleLicenseCollection(s, i, client, id)
-		default:
-			_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: getText("unknown_action")})
-		}
-	case "col":
-		switch action {
-		case "disputes":
-			handleCollectionDisputes(s, i, client, id)
-		case "show":
-			// allow sub-actions: show:disputes:<addr>:<uid>
-			// treat as open collection overview -> show collection
-			handleCollection(s, i, client, id)
-		default:
-			_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: getText("unknown_action")})
-		}
-	default:
-		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: getText("unknown_component")})
-	}
+// followupComponentError tells the clicker why the card was left unchanged.
+func followupComponentError(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: content, Flags: discordgo.MessageFlagsEphemeral})
 }
@@ -0,0 +1,118 @@
+package workers
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+// collection card tabs, keyed by the action of their tab button
+var collectionTabs = []struct {
+	Action   customid.Action
+	LabelKey string
+}{
+	{customid.ActionShow, "btn_overview"},
+	{customid.ActionDisputes, "btn_disputes"},
+}
+
+func handleCollection(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, addr string) {
+	sendCollectionCard(s, i, client, addr, customid.ActionShow)
+}
+
+func handleCollectionDisputes(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, addr string) {
+	sendCollectionCard(s, i, client, addr, customid.ActionDisputes)
+}
+
+func sendCollectionCard(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, addr string, tab customid.Action) {
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error(err)
+		return
+	}
+	embed, components, err := renderCollectionCard(i, client, addr, tab)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	if len(components) == 0 {
+		followupEmbed(s, i, embed)
+		return
+	}
+	msg, err := followupEmbedWithComponents(s, i, embed, components)
+	if err == nil && msg != nil {
+		scheduleExpiry(i, msg, models.ExpiryKindCollection, addr)
+	}
+}
+
+// renderCollectionCard renders one tab of the collection card. Unknown
+// addresses yield a warning embed without components.
+func renderCollectionCard(i *discordgo.InteractionCreate, client *storyclient.Client, addr string, tab customid.Action) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	var embed *discordgo.MessageEmbed
+	switch tab {
+	case customid.ActionDisputes:
+		item, err := client.GetCollectionDisputes(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if item == nil {
+			// Yellow warn for not found collection address
+			return &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_disputes"), Description: getTextWithCtx(i, "not_found"), Color: 0xFFFF00}, nil, nil
+		}
+		embed = &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_disputes"), addr), Color: 0x00AA00}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Raised", Value: fmt.Sprintf("%d", item.RaisedDisputeCount), Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Resolved", Value: fmt.Sprintf("%d", item.ResolvedDisputeCount), Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Cancelled", Value: fmt.Sprintf("%d", item.CancelledDisputeCount), Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Judged", Value: fmt.Sprintf("%d", item.JudgedDisputeCount), Inline: true})
+	default:
+		item, err := client.GetCollection(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if item == nil {
+			return &discordgo.MessageEmbed{Title: "Collection", Description: getTextWithCtx(i, "not_found"), Color: 0xFFFF00}, nil, nil
+		}
+		meta := &item.CollectionMetadata
+		recordLookup(i, models.LookupKindCollection, addr, meta.Name)
+		embed = &discordgo.MessageEmbed{Title: fmt.Sprintf("%s %s", getTextWithCtx(i, "title_collection"), addr), Color: accentColor(i, 0x0055FF)}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "name"), Value: meta.Name, Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "symbol"), Value: meta.Symbol, Inline: true})
+		if meta.TotalSupply != nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "total_supply"), Value: meta.TotalSupply.String(), Inline: true})
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "token_type"), Value: meta.TokenType, Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_asset_count"), Value: fmt.Sprint(item.AssetCount), Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_licenses_count"), Value: fmt.Sprint(item.LicensesCount), Inline: true})
+		if meta.CreatedAt != nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_created"), Value: meta.CreatedAt.UTC().Format("2006-01-02"), Inline: true})
+		}
+		if meta.UpdatedAt != nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_updated"), Value: meta.UpdatedAt.UTC().Format("2006-01-02"), Inline: true})
+		}
+	}
+
+	contract := linkRow(linkButton(i, "btn_contract", explorerLinks(i).Address(addr)))
+	if interactionUserID(i) == "" {
+		if contract == nil {
+			return embed, nil, nil
+		}
+		return embed, []discordgo.MessageComponent{contract}, nil
+	}
+	// tab row: the current tab is highlighted and disabled
+	var tabs []discordgo.MessageComponent
+	for _, t := range collectionTabs {
+		b := discordgo.Button{Label: getTextWithCtx(i, t.LabelKey), Style: discordgo.SecondaryButton, CustomID: componentID(i, customid.KindCollection, t.Action, addr)}
+		if t.Action == tab {
+			b.Style = discordgo.PrimaryButton
+			b.Disabled = true
+		}
+		tabs = append(tabs, b)
+	}
+	components := []discordgo.MessageComponent{discordgo.ActionsRow{Components: tabs}}
+	if contract != nil {
+		components = append(components, contract)
+	}
+	return embed, append(components, exportRow(i, customid.KindCollection, addr, uint32(tab))), nil
+}
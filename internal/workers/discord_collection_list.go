@@ -0,0 +1,199 @@
+package workers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	collectionsCommand = "collections"
+	// collectionsPageSize matches the buttons that fit one row, one per
+	// listed collection
+	collectionsPageSize = 5
+)
+
+func collectionsCommandDef() *discordgo.ApplicationCommand {
+	minValue := 0.0
+	return &discordgo.ApplicationCommand{
+		Name:        collectionsCommand,
+		Description: "List collections by number of assets",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionInteger, Name: "min_assets", Description: "Minimum number of assets", MinValue: &minValue},
+			{Type: discordgo.ApplicationCommandOptionInteger, Name: "max_assets", Description: "Maximum number of assets", MinValue: &minValue},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "order", Description: "Order by last update", Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Most recently updated", Value: "desc"},
+				{Name: "Least recently updated", Value: "asc"},
+			}},
+			privateOption,
+		},
+	}
+}
+
+// collectionFilter is the query of a /collections listing. It travels in the
+// custom_id target of the pager buttons as "min-max-order", with empty bounds
+// left out.
+type collectionFilter struct {
+	MinAssets int // -1 when unset
+	MaxAssets int // -1 when unset
+	Order     string
+}
+
+func (f collectionFilter) String() string {
+	bound := func(n int) string {
+		if n < 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%s-%s-%s", bound(f.MinAssets), bound(f.MaxAssets), f.Order)
+}
+
+func parseCollectionFilter(s string) (collectionFilter, error) {
+	f := collectionFilter{MinAssets: -1, MaxAssets: -1, Order: "desc"}
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 {
+		return f, fmt.Errorf("malformed collection filter %q", s)
+	}
+	for k, dst := range []*int{&f.MinAssets, &f.MaxAssets} {
+		if parts[k] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(parts[k])
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("malformed collection filter %q", s)
+		}
+		*dst = n
+	}
+	if parts[2] == "asc" {
+		f.Order = "asc"
+	}
+	return f, nil
+}
+
+func handleCollections(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	f := collectionFilter{MinAssets: -1, MaxAssets: -1, Order: "desc"}
+	for _, o := range i.ApplicationCommandData().Options {
+		switch o.Name {
+		case "min_assets":
+			f.MinAssets = int(o.IntValue())
+		case "max_assets":
+			f.MaxAssets = int(o.IntValue())
+		case "order":
+			f.Order = o.StringValue()
+		}
+	}
+	if f.MinAssets >= 0 && f.MaxAssets >= 0 && f.MinAssets > f.MaxAssets {
+		respondEphemeral(s, i, getTextWithCtx(i, "collections_bad_range"))
+		return
+	}
+
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error(err)
+		return
+	}
+	embed, components, err := renderCollectionList(i, client, f, 0)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	if len(components) == 0 {
+		followupEmbed(s, i, embed)
+		return
+	}
+	msg, err := followupEmbedWithComponents(s, i, embed, components)
+	if err == nil && msg != nil {
+		scheduleExpiry(i, msg, models.ExpiryKindCollectionList, f.String())
+	}
+}
+
+// renderCollectionList renders one page of collections matching f, with a
+// button per row opening the collection card and Prev/Next buttons.
+func renderCollectionList(i *discordgo.InteractionCreate, client *storyclient.Client, f collectionFilter, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	// one extra item tells whether a next page exists
+	items, err := client.ListCollections(f.MinAssets, f.MaxAssets, f.Order, collectionsPageSize+1, page*collectionsPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	hasNext := len(items) > collectionsPageSize
+	if hasNext {
+		items = items[:collectionsPageSize]
+	}
+
+	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_collections"), Color: accentColor(i, 0x0055FF)}
+	var filters []string
+	if f.MinAssets >= 0 {
+		filters = append(filters, fmt.Sprintf("≥ %d", f.MinAssets))
+	}
+	if f.MaxAssets >= 0 {
+		filters = append(filters, fmt.Sprintf("≤ %d", f.MaxAssets))
+	}
+	if len(filters) > 0 {
+		embed.Description = fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_asset_count"), strings.Join(filters, ", "))
+	}
+	if len(items) == 0 {
+		embed.Description = strings.TrimSpace(embed.Description + "\n" + getTextWithCtx(i, "collections_none"))
+		embed.Color = 0xFFFF00
+	}
+
+	var open []discordgo.MessageComponent
+	for k := range items {
+		item := &items[k]
+		n := page*collectionsPageSize + k + 1
+		embed.Fields = append(embed.Fields, collectionListField(i, n, item))
+		if addr := collectionItemAddress(item); addr != "" && interactionUserID(i) != "" {
+			open = append(open, discordgo.Button{Label: strconv.Itoa(n), Style: discordgo.SecondaryButton, CustomID: componentID(i, customid.KindCollection, customid.ActionShow, addr)})
+		}
+	}
+	if interactionUserID(i) == "" {
+		return embed, nil, nil
+	}
+
+	var components []discordgo.MessageComponent
+	if len(open) > 0 {
+		components = append(components, discordgo.ActionsRow{Components: open})
+	}
+	if page > 0 || hasNext {
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: getTextWithCtx(i, "btn_prev"), Style: discordgo.SecondaryButton, Disabled: page == 0,
+				CustomID: componentIDArg(i, customid.KindCollectionList, customid.ActionShow, f.String(), uint32(max(page-1, 0)))},
+			discordgo.Button{Label: getTextWithCtx(i, "btn_next"), Style: discordgo.SecondaryButton, Disabled: !hasNext,
+				CustomID: componentIDArg(i, customid.KindCollectionList, customid.ActionShow, f.String(), uint32(page+1))},
+		}})
+	}
+	return embed, components, nil
+}
+
+func collectionListField(i *discordgo.InteractionCreate, n int, item *dto.CollectionItem) *discordgo.MessageEmbedField {
+	name := item.CollectionMetadata.Name
+	if name == "" {
+		name = "?"
+	}
+	addr := collectionItemAddress(item)
+	if url := explorerLinks(i).Address(addr); url != "" {
+		addr = fmt.Sprintf("[%s](%s)", addr, url)
+	}
+	lines := []string{
+		addr,
+		fmt.Sprintf("%s: %d · %s: %d · %s: %d", getTextWithCtx(i, "embed_asset_count"), item.AssetCount,
+			getTextWithCtx(i, "embed_licenses_count"), item.LicensesCount, getTextWithCtx(i, "stats_disputes"), item.RaisedDisputeCount),
+	}
+	if item.CollectionMetadata.UpdatedAt != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_updated"), item.CollectionMetadata.UpdatedAt.UTC().Format("2006-01-02")))
+	}
+	return &discordgo.MessageEmbedField{Name: truncateRunes(fmt.Sprintf("%d. %s", n, name), 256), Value: strings.Join(lines, "\n")}
+}
+
+func collectionItemAddress(item *dto.CollectionItem) string {
+	if item.CollectionAddress != "" {
+		return item.CollectionAddress
+	}
+	return item.CollectionMetadata.Address
+}
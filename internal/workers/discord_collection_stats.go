@@ -0,0 +1,233 @@
+package workers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// /assets can only filter by IP IDs or owner, not by collection, so stats
+	// are computed from the collection's assets found among the most recent
+	// statsMaxPages pages of the network. Collections without recent mints
+	// get counters only. The pool is the same for every collection and is
+	// cached per network.
+	statsPageSize  = 200
+	statsMaxPages  = 2
+	statsSampleTTL = 10 * time.Minute
+	// statsTopTemplates is how many license templates the distribution lists
+	statsTopTemplates = 5
+)
+
+// collectionStats aggregates the sampled assets of a collection.
+type collectionStats struct {
+	Sampled      int
+	Scanned      int
+	Templates    map[string]int
+	Licensed     int
+	Commercial   int
+	RevShareSum  int
+	Verdicts     map[moderation.Verdict]int
+	NotModerated int
+}
+
+// statsSample is a cached pool of recent assets of one network.
+type statsSample struct {
+	assets  []dto.IPAsset
+	expires time.Time
+}
+
+var (
+	statsSamples   = map[string]*statsSample{}
+	statsSamplesMu sync.Mutex
+)
+
+func handleCollectionStats(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, addr string) {
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error(err)
+		return
+	}
+	item, err := client.GetCollection(addr)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	if item == nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_collection_stats"), Description: getTextWithCtx(i, "not_found"), Color: 0xFFFF00})
+		return
+	}
+	recordLookup(i, models.LookupKindCollection, addr, item.CollectionMetadata.Name)
+
+	assets, scanned, err := sampleCollectionAssets(client, addr)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	stats := aggregateCollectionStats(i, assets)
+	stats.Scanned = scanned
+	followupEmbed(s, i, collectionStatsEmbed(i, addr, item, stats))
+}
+
+// sampleCollectionAssets keeps the assets minted by the collection among
+// the recent assets of the client's network. It also returns how many recent
+// assets were looked at.
+func sampleCollectionAssets(client *storyclient.Client, addr string) ([]dto.IPAsset, int, error) {
+	recent, err := recentStatsAssets(client)
+	if err != nil {
+		return nil, 0, err
+	}
+	var out []dto.IPAsset
+	for k := range recent {
+		if strings.EqualFold(assetCollectionAddress(&recent[k]), addr) {
+			out = append(out, recent[k])
+		}
+	}
+	return out, len(recent), nil
+}
+
+// recentStatsAssets returns the cached pool of recent assets, fetching up to
+// statsMaxPages pages when it expired. The lock is held while fetching so
+// concurrent commands share one fetch.
+func recentStatsAssets(client *storyclient.Client) ([]dto.IPAsset, error) {
+	statsSamplesMu.Lock()
+	defer statsSamplesMu.Unlock()
+	key := client.Network().Name
+	if c, ok := statsSamples[key]; ok && time.Now().Before(c.expires) {
+		return c.assets, nil
+	}
+	var assets []dto.IPAsset
+	for page := 0; page < statsMaxPages; page++ {
+		batch, err := client.ListAssets(statsPageSize, page*statsPageSize)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, batch...)
+		if len(batch) < statsPageSize {
+			break
+		}
+	}
+	statsSamples[key] = &statsSample{assets: assets, expires: time.Now().Add(statsSampleTTL)}
+	return assets, nil
+}
+
+func aggregateCollectionStats(i *discordgo.InteractionCreate, assets []dto.IPAsset) collectionStats {
+	stats := collectionStats{Sampled: len(assets), Templates: map[string]int{}, Verdicts: map[moderation.Verdict]int{}}
+	for k := range assets {
+		asset := &assets[k]
+		if lt := storyclient.PrimaryLicense(asset); lt != nil {
+			stats.Licensed++
+			name := lt.TemplateName
+			if name == "" {
+				name = "?"
+			}
+			stats.Templates[strings.ToUpper(name)]++
+			if lt.Terms != nil && lt.Terms.CommercialUse {
+				stats.Commercial++
+				stats.RevShareSum += lt.Terms.CommercialRevShare
+			}
+		}
+		if asset.Moderation == nil {
+			stats.NotModerated++
+		} else {
+			stats.Verdicts[evaluateModeration(i, asset.Moderation).Verdict]++
+		}
+	}
+	return stats
+}
+
+func collectionStatsEmbed(i *discordgo.InteractionCreate, addr string, item *dto.CollectionItem, stats collectionStats) *discordgo.MessageEmbed {
+	title := addr
+	if item.CollectionMetadata.Name != "" {
+		title = item.CollectionMetadata.Name
+	}
+	description := fmt.Sprintf(getTextWithCtx(i, "stats_sampled"), stats.Sampled, item.AssetCount, stats.Scanned)
+	if stats.Sampled == 0 {
+		description = fmt.Sprintf(getTextWithCtx(i, "stats_sample_empty"), stats.Scanned)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_collection_stats"), title),
+		Description: description,
+		Color:       accentColor(i, 0x0055FF),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: getTextWithCtx(i, "embed_asset_count"), Value: fmt.Sprint(item.AssetCount), Inline: true},
+			{Name: getTextWithCtx(i, "embed_licenses_count"), Value: fmt.Sprint(item.LicensesCount), Inline: true},
+		},
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "stats_disputes"), Value: disputeRatios(i, item)})
+	if stats.Sampled == 0 {
+		return embed
+	}
+
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "stats_templates"), Value: templateDistribution(i, stats)})
+	commercial := fmt.Sprintf("%d/%d (%s)", stats.Commercial, stats.Sampled, percent(stats.Commercial, stats.Sampled))
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_commercial_use"), Value: commercial, Inline: true})
+	if stats.Commercial > 0 {
+		avg := formatRevSharePercent(stats.RevShareSum / stats.Commercial)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "stats_avg_rev_share"), Value: avg, Inline: true})
+	}
+	var verdicts []string
+	for _, v := range []moderation.Verdict{moderation.Safe, moderation.Review, moderation.Unsafe} {
+		verdicts = append(verdicts, fmt.Sprintf("%s: %d", v, stats.Verdicts[v]))
+	}
+	verdicts = append(verdicts, fmt.Sprintf("%s: %d", getTextWithCtx(i, "stats_not_moderated"), stats.NotModerated))
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "title_moderation"), Value: strings.Join(verdicts, "\n"), Inline: true})
+	return embed
+}
+
+// templateDistribution lists the most used license templates with their share
+// of the sample; assets without a license are counted separately.
+func templateDistribution(i *discordgo.InteractionCreate, stats collectionStats) string {
+	names := make([]string, 0, len(stats.Templates))
+	for name := range stats.Templates {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		if stats.Templates[names[a]] != stats.Templates[names[b]] {
+			return stats.Templates[names[a]] > stats.Templates[names[b]]
+		}
+		return names[a] < names[b]
+	})
+	var lines []string
+	for k, name := range names {
+		if k == statsTopTemplates {
+			break
+		}
+		n := stats.Templates[name]
+		lines = append(lines, fmt.Sprintf("%s — %d (%s)", name, n, percent(n, stats.Sampled)))
+	}
+	if unlicensed := stats.Sampled - stats.Licensed; unlicensed > 0 {
+		lines = append(lines, fmt.Sprintf("%s — %d (%s)", getTextWithCtx(i, "stats_unlicensed"), unlicensed, percent(unlicensed, stats.Sampled)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// disputeRatios relates the collection's dispute counters to its size and to
+// each other.
+func disputeRatios(i *discordgo.InteractionCreate, item *dto.CollectionItem) string {
+	raised := item.RaisedDisputeCount
+	lines := []string{fmt.Sprintf(getTextWithCtx(i, "stats_disputes_per_asset"), raised, percent(raised, item.AssetCount))}
+	if raised > 0 {
+		lines = append(lines,
+			fmt.Sprintf("%s: %d (%s)", getTextWithCtx(i, "disputes_judged"), item.JudgedDisputeCount, percent(item.JudgedDisputeCount, raised)),
+			fmt.Sprintf("%s: %d (%s)", getTextWithCtx(i, "disputes_resolved"), item.ResolvedDisputeCount, percent(item.ResolvedDisputeCount, raised)),
+			fmt.Sprintf("%s: %d (%s)", getTextWithCtx(i, "disputes_cancelled"), item.CancelledDisputeCount, percent(item.CancelledDisputeCount, raised)),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func percent(n, total int) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,244 @@
+package workers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	compareCommand = "compare"
+	// compareChunkRunes bounds the rows listed in one embed description
+	compareChunkRunes = 3500
+	// compareMessageRunes bounds the descriptions sent in one message, which
+	// leaves room for titles, fields and footers below Discord's 6000 limit
+	compareMessageRunes = 5000
+)
+
+func compareCommandDef() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        compareCommand,
+		Description: "Compare the license terms of two IP assets",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "ip_a", Description: "First IP ID", Required: true, Autocomplete: true},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "ip_b", Description: "Second IP ID", Required: true, Autocomplete: true},
+			privateOption,
+		},
+	}
+}
+
+func handleCompare(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	var ids [2]string
+	for _, o := range i.ApplicationCommandData().Options {
+		switch o.Name {
+		case "ip_a":
+			ids[0] = strings.TrimSpace(o.StringValue())
+		case "ip_b":
+			ids[1] = strings.TrimSpace(o.StringValue())
+		}
+	}
+	if ids[0] == "" || ids[1] == "" {
+		respondEphemeral(s, i, getTextWithCtx(i, "missing_param"))
+		return
+	}
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+
+	var (
+		assets [2]*dto.IPAsset
+		errs   [2]error
+		wg     sync.WaitGroup
+	)
+	for k := range ids {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			assets[k], errs[k] = fetchAsset(client, ids[k])
+		}(k)
+	}
+	wg.Wait()
+	for k := range ids {
+		if errs[k] != nil {
+			followupAssetError(s, i, errs[k])
+			return
+		}
+		if assets[k] == nil {
+			followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_compare"), Description: fmt.Sprintf("%s: %s", getTextWithCtx(i, "not_found"), ids[k]), Color: 0xFFFF00})
+			return
+		}
+		recordLookup(i, models.LookupKindIP, ids[k], assets[k].Title)
+	}
+	for _, embeds := range groupEmbeds(compareEmbeds(i, assets[0], assets[1]), compareMessageRunes) {
+		followupEmbeds(s, i, embeds)
+	}
+}
+
+// compareRow is one compared value of both assets. Addresses are compared
+// case-insensitively, everything else exactly.
+type compareRow struct {
+	Label   string
+	A, B    string
+	Address bool
+}
+
+func (r compareRow) equal() bool {
+	if r.Address {
+		return strings.EqualFold(r.A, r.B)
+	}
+	return r.A == r.B
+}
+
+// compareRows lists the compared values of both assets.
+func compareRows(i *discordgo.InteractionCreate, a, b *dto.IPAsset) []compareRow {
+	rows := []compareRow{
+		{Label: getTextWithCtx(i, "name"), A: a.Title, B: b.Title},
+		{Label: getTextWithCtx(i, "embed_owner"), A: a.OwnerAddress, B: b.OwnerAddress, Address: true},
+	}
+
+	// PIL fields present on either side, in LicenseFields order
+	fa := storyclient.LicenseFields(storyclient.PrimaryLicense(a))
+	fb := storyclient.LicenseFields(storyclient.PrimaryLicense(b))
+	vb := map[string]string{}
+	for _, f := range fb {
+		vb[f.Name] = f.Value
+	}
+	seen := map[string]struct{}{}
+	for _, f := range fa {
+		seen[f.Name] = struct{}{}
+		rows = append(rows, compareRow{Label: f.Name, A: f.Value, B: vb[f.Name]})
+	}
+	for _, f := range fb {
+		if _, ok := seen[f.Name]; !ok {
+			rows = append(rows, compareRow{Label: f.Name, B: f.Value})
+		}
+	}
+
+	verdict := func(x *dto.IPAsset) string {
+		if x.Moderation == nil {
+			return ""
+		}
+		return moderationSummary(evaluateModeration(i, x.Moderation))
+	}
+	infringement := func(x *dto.IPAsset) string {
+		if len(x.Infringement) == 0 {
+			return ""
+		}
+		status, _ := infringementStatus(x.Infringement)
+		return status
+	}
+	collection := func(x *dto.IPAsset) string {
+		if c := storyclient.AssetCollection(x); c != nil && c.Name != "" {
+			return c.Name
+		}
+		return ""
+	}
+	contract := func(x *dto.IPAsset) string {
+		if c := storyclient.AssetContract(x); c != nil {
+			return c.Address
+		}
+		return ""
+	}
+	return append(rows,
+		compareRow{Label: getTextWithCtx(i, "title_moderation"), A: verdict(a), B: verdict(b)},
+		compareRow{Label: getTextWithCtx(i, "title_infringement"), A: infringement(a), B: infringement(b)},
+		compareRow{Label: getTextWithCtx(i, "collection_name"), A: collection(a), B: collection(b)},
+		compareRow{Label: getTextWithCtx(i, "contract_address"), A: contract(a), B: contract(b), Address: true},
+	)
+}
+
+// compareEmbeds lists every row with differences marked, and warns about the
+// same title registered by different owners. Rows that do not fit one
+// description continue in further embeds.
+func compareEmbeds(i *discordgo.InteractionCreate, a, b *dto.IPAsset) []*discordgo.MessageEmbed {
+	rows := compareRows(i, a, b)
+	diffs := 0
+	var lines []string
+	for _, r := range rows {
+		va, vb := r.A, r.B
+		if va == "" {
+			va = "—"
+		}
+		if vb == "" {
+			vb = "—"
+		}
+		if r.equal() {
+			lines = append(lines, fmt.Sprintf("▫️ %s: %s", r.Label, va))
+			continue
+		}
+		diffs++
+		lines = append(lines, fmt.Sprintf("🔸 **%s**: %s ⟷ %s", r.Label, va, vb))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: getTextWithCtx(i, "title_compare"),
+		Color: accentColor(i, 0x00FF00),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "A", Value: a.IpId, Inline: true},
+			{Name: "B", Value: b.IpId, Inline: true},
+			{Name: getTextWithCtx(i, "compare_differences"), Value: fmt.Sprint(diffs), Inline: true},
+		},
+	}
+	if diffs > 0 {
+		embed.Color = 0xFFAA00
+	}
+	if a.Title != "" && a.Title == b.Title && !strings.EqualFold(a.OwnerAddress, b.OwnerAddress) {
+		lines = append([]string{getTextWithCtx(i, "compare_copycat"), ""}, lines...)
+		embed.Color = 0xFF0000
+	}
+	chunks := packLines(lines, compareChunkRunes)
+	embed.Description = chunks[0]
+	embeds := []*discordgo.MessageEmbed{embed}
+	for _, c := range chunks[1:] {
+		embeds = append(embeds, &discordgo.MessageEmbed{Description: c, Color: embed.Color})
+	}
+	return embeds
+}
+
+// packLines joins lines into chunks of at most n runes without splitting a
+// line; a single line longer than n is truncated. It returns at least one chunk.
+func packLines(lines []string, n int) []string {
+	chunks := []string{""}
+	size := 0
+	for _, l := range lines {
+		l = truncateRunes(l, n)
+		ln := utf8.RuneCountInString(l)
+		if size > 0 && size+1+ln > n {
+			chunks = append(chunks, "")
+			size = 0
+		}
+		last := &chunks[len(chunks)-1]
+		if size > 0 {
+			*last += "\n"
+			size++
+		}
+		*last += l
+		size += ln
+	}
+	return chunks
+}
+
+// groupEmbeds splits embeds into messages whose descriptions total at most n
+// runes, keeping at least one embed per message and at most Discord's 10.
+func groupEmbeds(embeds []*discordgo.MessageEmbed, n int) [][]*discordgo.MessageEmbed {
+	var out [][]*discordgo.MessageEmbed
+	size := 0
+	for _, e := range embeds {
+		ln := utf8.RuneCountInString(e.Description)
+		if k := len(out); k == 0 || size+ln > n || len(out[k-1]) == 10 {
+			out = append(out, nil)
+			size = 0
+		}
+		out[len(out)-1] = append(out[len(out)-1], e)
+		size += ln
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package workers
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/configs"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	"github.com/goldsheva/discord-story-bot/internal/network"
+)
+
+var (
+	codec     *customid.Codec
+	codecOnce sync.Once
+)
+
+// componentCodec signs custom_ids with CUSTOM_ID_SECRET, or a key derived
+// from the bot token when no secret is configured.
+func componentCodec() *customid.Codec {
+	codecOnce.Do(func() {
+		config := configs.GetEnvConfig()
+		secret := []byte(config.CUSTOM_ID_SECRET)
+		if len(secret) == 0 {
+			sum := sha256.Sum256([]byte("custom_id:" + config.BOT_TOKEN))
+			secret = sum[:]
+		}
+		codec = customid.New(secret)
+	})
+	return codec
+}
+
+// componentID builds a custom_id usable only by the interaction user until the
+// guild's button timeout elapses.
+func componentID(i *discordgo.InteractionCreate, kind customid.Kind, action customid.Action, target string) string {
+	return componentIDArg(i, kind, action, target, 0)
+}
+
+// componentIDArg is componentID carrying an action argument.
+func componentIDArg(i *discordgo.InteractionCreate, kind customid.Kind, action customid.Action, target string, arg uint32) string {
+	return encodeComponentID(customid.Payload{
+		Kind:    kind,
+		Action:  action,
+		Target:  target,
+		Owner:   interactionUserID(i),
+		Expires: time.Now().Add(resolveSettings(i).ButtonTimeout),
+		Arg:     arg,
+		Network: network.Networks().Index(interactionNetwork(i)),
+	})
+}
+
+// encodeComponentID signs p. On failure it logs and returns "";
+// validComponents drops components left without an ID before sending, as
+// Discord would reject the whole message for one of them.
+func encodeComponentID(p customid.Payload) string {
+	id, err := componentCodec().Encode(p)
+	if err != nil {
+		log.Errorf("failed to encode custom_id for %q: %v", p.Target, err)
+	}
+	return id
+}
+
+// validComponents returns the rows of components without the buttons and
+// select menus that have no custom_id, leaving out rows that end up empty.
+func validComponents(components []discordgo.MessageComponent) []discordgo.MessageComponent {
+	var out []discordgo.MessageComponent
+	for _, c := range components {
+		ar, ok := c.(discordgo.ActionsRow)
+		if !ok {
+			out = append(out, c)
+			continue
+		}
+		var row []discordgo.MessageComponent
+		for _, rc := range ar.Components {
+			switch v := rc.(type) {
+			case discordgo.Button:
+				if v.Style != discordgo.LinkButton && v.CustomID == "" {
+					continue
+				}
+			case discordgo.SelectMenu:
+				if v.CustomID == "" {
+					continue
+				}
+			}
+			row = append(row, rc)
+		}
+		if len(row) > 0 {
+			out = append(out, discordgo.ActionsRow{Components: row})
+		}
+	}
+	return out
+}
+
+func decodeComponentID(id string) (customid.Payload, error) {
+	return componentCodec().Decode(id)
+}
@@ -0,0 +1,128 @@
+package workers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	checkLicensesCommand = "Check licenses"
+	// a message can carry at most 10 embeds
+	maxEmbedsPerMessage = 10
+)
+
+// ipIDPattern matches bare IP ids as well as ids inside Story explorer, portal
+// and Storyscan URLs, which all carry the id as a 0x path segment. The trailing
+// word boundary skips 32-byte tx hashes.
+var ipIDPattern = regexp.MustCompile(`(?i)\b0x[0-9a-f]{40}\b`)
+
+func checkLicensesMessageCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{Name: checkLicensesCommand, Type: discordgo.MessageApplicationCommand}
+}
+
+// extractIPIDs returns unique IP ids found in text, in order of appearance.
+func extractIPIDs(text string) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, m := range ipIDPattern.FindAllString(text, -1) {
+		key := strings.ToLower(m)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, m)
+	}
+	return out
+}
+
+// messageText collects the searchable text of a message including its embeds.
+func messageText(m *discordgo.Message) string {
+	parts := []string{m.Content}
+	for _, e := range m.Embeds {
+		parts = append(parts, e.URL, e.Title, e.Description)
+		for _, f := range e.Fields {
+			parts = append(parts, f.Value)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func handleCheckLicensesMessage(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	data := i.ApplicationCommandData()
+	var target *discordgo.Message
+	if data.Resolved != nil {
+		target = data.Resolved.Messages[data.TargetID]
+	}
+	if target == nil {
+		respondEphemeral(s, i, getTextWithCtx(i, "invalid_component"))
+		return
+	}
+	ids := extractIPIDs(messageText(target))
+	if len(ids) == 0 {
+		respondEphemeral(s, i, getTextWithCtx(i, "no_ip_ids_in_message"))
+		return
+	}
+	if len(ids) > maxEmbedsPerMessage {
+		ids = ids[:maxEmbedsPerMessage]
+	}
+
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	assets, err := fetchAssets(client, ids)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	byID := map[string]*dto.IPAsset{}
+	for k := range assets {
+		byID[strings.ToLower(assets[k].IpId)] = &assets[k]
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	var missing []string
+	for _, id := range ids {
+		asset, ok := byID[strings.ToLower(id)]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		recordLookup(i, models.LookupKindIP, id, asset.Title)
+		embeds = append(embeds, compactLicenseEmbed(i, asset, id))
+	}
+	if len(missing) > 0 {
+		warn := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "not_found"), Description: strings.Join(missing, "\n"), Color: 0xFFFF00}
+		if len(embeds) >= maxEmbedsPerMessage {
+			embeds = embeds[:maxEmbedsPerMessage-1]
+		}
+		embeds = append(embeds, warn)
+	}
+	followupEmbeds(s, i, embeds)
+}
+
+// compactLicenseEmbed is the license card with a shortened description and a
+// one-field summary of the primary license terms.
+func compactLicenseEmbed(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) *discordgo.MessageEmbed {
+	embed, _ := buildLicenseEmbed(i, asset, ipId)
+	embed.Description = truncateRunes(embed.Description, 200)
+	if lt := storyclient.PrimaryLicense(asset); lt != nil && lt.Terms != nil {
+		yes := map[bool]string{true: "✅", false: "❌"}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: getTextWithCtx(i, "title_terms"),
+			Value: fmt.Sprintf("%s: %s\n%s: %s\n%s: %s",
+				getTextWithCtx(i, "embed_commercial_use"), yes[lt.Terms.CommercialUse],
+				getTextWithCtx(i, "embed_derivatives_allowed"), yes[lt.Terms.DerivativesAllowed],
+				getTextWithCtx(i, "embed_commercial_rev_share"), formatRevSharePercent(lt.Terms.CommercialRevShare)),
+			Inline: false,
+		})
+	}
+	return embed
+}
@@ -0,0 +1,136 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/network"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	expirySweepInterval = 30 * time.Second
+	expirySweepBatch    = 50
+	// give up on a message after this many failed delete/edit attempts
+	expiryMaxAttempts = 3
+)
+
+// scheduleExpiry persists when the buttons of msg stop being live.
+func scheduleExpiry(i *discordgo.InteractionCreate, msg *discordgo.Message, kind, target string) {
+	// ephemeral messages can't be edited through the channel API and vanish on their own
+	if msg.Flags&discordgo.MessageFlagsEphemeral != 0 {
+		return
+	}
+	rec := &models.MessageExpiry{
+		MessageID: msg.ID,
+		ChannelID: msg.ChannelID,
+		GuildID:   i.GuildID,
+		Kind:      kind,
+		Target:    target,
+		Network:   interactionNetwork(i).Name,
+		OwnerID:   interactionUserID(i),
+		ExpiresAt: time.Now().UTC().Add(resolveSettings(i).ButtonTimeout),
+	}
+	if err := database.SaveMessageExpiry(rec); err != nil {
+		log.Errorf("failed to save message expiry: %v", err)
+	}
+}
+
+// runExpirySweeper handles expired messages on startup and then periodically.
+func runExpirySweeper(ctx context.Context, s *discordgo.Session) {
+	sweepExpiredMessages(s)
+	ticker := time.NewTicker(expirySweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sweepExpiredMessages(s)
+		}
+	}
+}
+
+func sweepExpiredMessages(s *discordgo.Session) {
+	recs, err := database.DueMessageExpiries(time.Now().UTC(), expirySweepBatch)
+	if err != nil {
+		log.Errorf("failed to load expired messages: %v", err)
+		return
+	}
+	for k := range recs {
+		expireMessage(s, &recs[k])
+	}
+}
+
+func expireMessage(s *discordgo.Session, rec *models.MessageExpiry) {
+	var done bool
+	if rec.Kind == models.ExpiryKindLicense && resolveGuildSettings(rec.GuildID).ExpiryMode == models.ExpiryModeRefresh {
+		done = offerRefresh(s, rec)
+	} else {
+		done = deleteOrDisable(s, rec)
+	}
+
+	rec.Attempts++
+	if done || rec.Attempts >= expiryMaxAttempts {
+		if err := database.DeleteMessageExpiry(rec.MessageID); err != nil {
+			log.Errorf("failed to delete message expiry: %v", err)
+		}
+		return
+	}
+	if err := database.SaveMessageExpiry(rec); err != nil {
+		log.Errorf("failed to save message expiry: %v", err)
+	}
+}
+
+// deleteOrDisable deletes the message, falling back to disabling its components.
+func deleteOrDisable(s *discordgo.Session, rec *models.MessageExpiry) bool {
+	derr := s.ChannelMessageDelete(rec.ChannelID, rec.MessageID)
+	if derr == nil || isUnknownMessage(derr) {
+		return true
+	}
+	logrus.Debugf("failed to delete message: %v, falling back to disabling components", derr)
+	msg, err := s.ChannelMessage(rec.ChannelID, rec.MessageID)
+	if err != nil {
+		logrus.Debugf("failed to fetch message: %v", err)
+		return isUnknownMessage(err)
+	}
+	disabled := disableComponentsCopy(msg.Components)
+	if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: rec.MessageID, Channel: rec.ChannelID, Components: &disabled}); err != nil {
+		logrus.Debugf("failed to disable components: %v", err)
+		return isUnknownMessage(err)
+	}
+	return true
+}
+
+// offerRefresh disables the card buttons and adds a Refresh button anyone can use.
+func offerRefresh(s *discordgo.Session, rec *models.MessageExpiry) bool {
+	msg, err := s.ChannelMessage(rec.ChannelID, rec.MessageID)
+	if err != nil {
+		logrus.Debugf("failed to fetch message: %v", err)
+		return isUnknownMessage(err)
+	}
+	ctx := guildInteraction(rec.GuildID)
+	var net uint8
+	if n, ok := network.Networks().Lookup(rec.Network); ok {
+		net = network.Networks().Index(n)
+	}
+	comps := disableComponentsCopy(msg.Components)
+	comps = validComponents(append(comps, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{Label: getTextWithCtx(ctx, "btn_refresh"), Style: discordgo.SecondaryButton, CustomID: encodeComponentID(customid.Payload{Kind: customid.KindLicense, Action: customid.ActionRefresh, Target: rec.Target, Network: net})},
+	}}))
+	if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: rec.MessageID, Channel: rec.ChannelID, Components: &comps}); err != nil {
+		logrus.Debugf("failed to add refresh button: %v", err)
+		return isUnknownMessage(err)
+	}
+	return true
+}
+
+func isUnknownMessage(err error) bool {
+	var re *discordgo.RESTError
+	return errors.As(err, &re) && re.Message != nil && re.Message.Code == discordgo.ErrCodeUnknownMessage
+}
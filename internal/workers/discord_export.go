@@ -0,0 +1,97 @@
+package workers
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	"github.com/goldsheva/discord-story-bot/internal/export"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+var exportFormatLabels = map[export.Format]string{
+	export.FormatJSON:     "JSON",
+	export.FormatCSV:      "CSV",
+	export.FormatMarkdown: "Markdown",
+}
+
+// exportRow is the Export menu of a card. arg tells the handler which data
+// the card shows, e.g. the collection tab.
+func exportRow(i *discordgo.InteractionCreate, kind customid.Kind, target string, arg uint32) discordgo.MessageComponent {
+	var options []discordgo.SelectMenuOption
+	for _, f := range export.Formats {
+		options = append(options, discordgo.SelectMenuOption{Label: exportFormatLabels[f], Value: string(f)})
+	}
+	return discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.SelectMenu{
+			MenuType:    discordgo.StringSelectMenu,
+			CustomID:    componentIDArg(i, kind, customid.ActionExport, target, arg),
+			Placeholder: getTextWithCtx(i, "select_export"),
+			Options:     options,
+		},
+	}}
+}
+
+// handleExport sends the data behind a card as an attachment only the clicker sees.
+func handleExport(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, p customid.Payload) {
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	}); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	format := export.FormatJSON
+	if values := i.MessageComponentData().Values; len(values) > 0 {
+		format = export.Format(values[0])
+	}
+
+	h := export.Header{Subject: p.Target, FetchedAt: time.Now().UTC(), APIBase: client.BaseURL()}
+	var (
+		data  interface{}
+		found bool
+		err   error
+	)
+	switch {
+	case p.Kind == customid.KindLicense:
+		h.Title = "License"
+		asset, ferr := fetchAsset(client, p.Target)
+		data, found, err = asset, asset != nil, ferr
+	case p.Kind == customid.KindCollection && customid.Action(p.Arg) == customid.ActionDisputes:
+		h.Title = "Collection disputes"
+		item, ferr := client.GetCollectionDisputes(p.Target)
+		data, found, err = item, item != nil, ferr
+	case p.Kind == customid.KindCollection:
+		h.Title = "Collection"
+		meta, ferr := client.GetCollectionByAddress(p.Target)
+		data, found, err = meta, meta != nil, ferr
+	default:
+		followupComponentError(s, i, getTextWithCtx(i, "unknown_action"))
+		return
+	}
+	if err != nil {
+		followupComponentError(s, i, err.Error())
+		return
+	}
+	if !found {
+		followupComponentError(s, i, getTextWithCtx(i, "not_found"))
+		return
+	}
+
+	body, name, err := export.Render(format, h, data)
+	if err != nil {
+		followupComponentError(s, i, err.Error())
+		return
+	}
+	_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: fmt.Sprintf(getTextWithCtx(i, "export_ready"), h.Title, h.FetchedAt.Format(time.RFC3339)),
+		Files:   []*discordgo.File{{Name: name, Reader: bytes.NewReader(body)}},
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		logrus.Error("Failed to send export: ", err)
+	}
+}
@@ -0,0 +1,283 @@
+package workers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// licenseHistoryLimit is how many changes /license_history shows.
+	licenseHistoryLimit = 10
+	// licenseHistoryScan is how many snapshots, across all licenses of an
+	// asset, are loaded to find those changes.
+	licenseHistoryScan = 100
+
+	// snapshot writes are queued and stored in batches off the request path
+	licenseSnapshotQueue = 64
+	licenseSnapshotBatch = 400
+	licenseSnapshotFlush = 2 * time.Second
+	// licenseHistoryWait bounds how long /license_history waits for the
+	// current observation to be stored
+	licenseHistoryWait = 5 * time.Second
+)
+
+// licenseSnapshotJob is a set of observed licenses; done, when set, is closed
+// once they are stored.
+type licenseSnapshotJob struct {
+	rows []models.LicenseSnapshot
+	done chan struct{}
+}
+
+var licenseSnapshots = make(chan licenseSnapshotJob, licenseSnapshotQueue)
+
+// fetchAsset fetches an asset and records a snapshot of its license terms.
+// All asset lookups go through here so the history sees every observation.
+func fetchAsset(client *storyclient.Client, ipID string) (*dto.IPAsset, error) {
+	asset, err := client.GetAssetByID(ipID)
+	if err == nil && asset != nil {
+		queueLicenseSnapshots(licenseSnapshotRows(client.Network().Name, asset), nil)
+	}
+	return asset, err
+}
+
+// fetchAssets is fetchAsset for many ids.
+func fetchAssets(client *storyclient.Client, ipIDs []string) ([]dto.IPAsset, error) {
+	assets, err := client.GetAssetsByIDs(ipIDs)
+	var rows []models.LicenseSnapshot
+	for k := range assets {
+		rows = append(rows, licenseSnapshotRows(client.Network().Name, &assets[k])...)
+	}
+	queueLicenseSnapshots(rows, nil)
+	return assets, err
+}
+
+// licenseSnapshotRows hashes every license terms set attached to the asset.
+func licenseSnapshotRows(networkName string, asset *dto.IPAsset) []models.LicenseSnapshot {
+	if asset.IpId == "" {
+		return nil
+	}
+	licenses := make([]*dto.LicenseTermsWrapper, 0, len(asset.Licenses))
+	for k := range asset.Licenses {
+		licenses = append(licenses, &asset.Licenses[k])
+	}
+	if len(licenses) == 0 {
+		if lt := storyclient.PrimaryLicense(asset); lt != nil {
+			licenses = append(licenses, lt)
+		}
+	}
+	var rows []models.LicenseSnapshot
+	for _, lt := range licenses {
+		fields := storyclient.LicenseFields(lt)
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			logrus.Error("failed to encode license snapshot: ", err)
+			continue
+		}
+		h := sha256.New()
+		for _, f := range fields {
+			fmt.Fprintf(h, "%s=%s\n", f.Name, f.Value)
+		}
+		rows = append(rows, models.LicenseSnapshot{
+			Network:        networkName,
+			IPID:           strings.ToLower(asset.IpId),
+			LicenseTermsID: lt.LicenseTermsId,
+			Hash:           hex.EncodeToString(h.Sum(nil)),
+			Fields:         string(raw),
+		})
+	}
+	return rows
+}
+
+// queueLicenseSnapshots hands rows to the snapshot writer without blocking.
+// When the queue is full the observation is dropped; the next lookup of the
+// asset records it again.
+func queueLicenseSnapshots(rows []models.LicenseSnapshot, done chan struct{}) bool {
+	if len(rows) == 0 {
+		return false
+	}
+	select {
+	case licenseSnapshots <- licenseSnapshotJob{rows: rows, done: done}:
+		return true
+	default:
+		log.Warnf("license snapshot queue full, dropping %d observations", len(rows))
+		return false
+	}
+}
+
+// runLicenseSnapshots is the single writer of license snapshots. It stores
+// queued observations in batches, so lookups never wait for the database and
+// concurrent lookups of one asset can't store the same version twice.
+func runLicenseSnapshots(ctx context.Context) {
+	var (
+		pending []models.LicenseSnapshot
+		waiting []chan struct{}
+	)
+	flush := func() {
+		if len(pending) > 0 {
+			if _, err := database.SaveLicenseSnapshots(pending); err != nil {
+				log.Errorf("failed to save license snapshots: %v", err)
+			}
+		}
+		for _, done := range waiting {
+			close(done)
+		}
+		pending, waiting = nil, nil
+	}
+	ticker := time.NewTicker(licenseSnapshotFlush)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// store what was already observed before shutting down
+			for {
+				select {
+				case job := <-licenseSnapshots:
+					pending = append(pending, job.rows...)
+					if job.done != nil {
+						waiting = append(waiting, job.done)
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case job := <-licenseSnapshots:
+			pending = append(pending, job.rows...)
+			if job.done != nil {
+				waiting = append(waiting, job.done)
+				flush()
+			} else if len(pending) >= licenseSnapshotBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func handleLicenseHistory(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	asset, err := client.GetAssetByID(ipId)
+	if err != nil {
+		followupAssetError(s, i, err)
+		return
+	}
+	if asset == nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_license_history"), Description: getTextWithCtx(i, "not_found"), Color: 0xFFFF00})
+		return
+	}
+	recordLookup(i, models.LookupKindIP, ipId, asset.Title)
+	// the current state is the newest observation, so wait until it is stored
+	done := make(chan struct{})
+	if queueLicenseSnapshots(licenseSnapshotRows(client.Network().Name, asset), done) {
+		select {
+		case <-done:
+		case <-time.After(licenseHistoryWait):
+		}
+	}
+	snaps, err := database.LicenseSnapshots(client.Network().Name, strings.ToLower(asset.IpId), licenseHistoryScan)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_license_history"), ipId), Color: accentColor(i, 0x00AAFF)}
+	changed := false
+	for k, snap := range snaps {
+		if len(embed.Fields) == licenseHistoryLimit {
+			break
+		}
+		// each license has its own history: diff against its previous version
+		var older *models.LicenseSnapshot
+		for j := k + 1; j < len(snaps); j++ {
+			if snaps[j].LicenseTermsID == snap.LicenseTermsID {
+				older = &snaps[j]
+				break
+			}
+		}
+		name := truncateRunes(fmt.Sprintf("%s · %s", snap.ObservedAt.UTC().Format("2006-01-02 15:04 UTC"), snapshotLabel(snap)), 256)
+		if older == nil {
+			// the first version may lie beyond what was loaded
+			if len(snaps) < licenseHistoryScan {
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: getTextWithCtx(i, "history_first_observed")})
+			}
+			continue
+		}
+		changed = true
+		diff := snapshotDiff(*older, snap)
+		if diff == "" {
+			diff = "—"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: truncateRunes(diff, 1024)})
+	}
+	if !changed {
+		embed.Description = getTextWithCtx(i, "history_no_changes")
+	}
+	_, _ = followupEmbedWithComponents(s, i, embed, nil)
+}
+
+// snapshotLabel names the license of a snapshot by template and terms id.
+func snapshotLabel(snap models.LicenseSnapshot) string {
+	var fields []storyclient.LicenseField
+	_ = json.Unmarshal([]byte(snap.Fields), &fields)
+	label := ""
+	for _, f := range fields {
+		if f.Name == "templateName" {
+			label = f.Value
+			break
+		}
+	}
+	if snap.LicenseTermsID != "" {
+		label = strings.TrimSpace(label + " #" + snap.LicenseTermsID)
+	}
+	if label == "" {
+		return "—"
+	}
+	return label
+}
+
+// snapshotDiff lists fields that differ between two snapshots, one per line.
+func snapshotDiff(older, newer models.LicenseSnapshot) string {
+	var of, nf []storyclient.LicenseField
+	_ = json.Unmarshal([]byte(older.Fields), &of)
+	_ = json.Unmarshal([]byte(newer.Fields), &nf)
+	ov := map[string]string{}
+	for _, f := range of {
+		ov[f.Name] = f.Value
+	}
+	show := func(v string) string {
+		if v == "" {
+			return "∅"
+		}
+		return v
+	}
+	var lines []string
+	seen := map[string]struct{}{}
+	for _, f := range nf {
+		seen[f.Name] = struct{}{}
+		if old, ok := ov[f.Name]; !ok || old != f.Value {
+			lines = append(lines, fmt.Sprintf("`%s`: %s → %s", f.Name, show(ov[f.Name]), show(f.Value)))
+		}
+	}
+	for _, f := range of {
+		if _, ok := seen[f.Name]; !ok {
+			lines = append(lines, fmt.Sprintf("`%s`: %s → ∅", f.Name, show(f.Value)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,273 @@
+package workers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/network"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	latestCommand = "latest"
+	// latestFetchSize is how many recent assets are fetched before filtering
+	// by media type
+	latestFetchSize = 50
+	// latestMaxCount is the number of embeds Discord allows per message
+	latestMaxCount     = 10
+	latestDefaultCount = 5
+
+	feedPollInterval = time.Minute
+	// feedMaxPages bounds how far one poll pages back to reach the assets
+	// posted last time
+	feedMaxPages = 20
+)
+
+var mediaTypeChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "Image", Value: "image"},
+	{Name: "Video", Value: "video"},
+	{Name: "Audio", Value: "audio"},
+}
+
+func latestCommandDef() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        latestCommand,
+		Description: "Show the most recently registered IP assets",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "media_type", Description: "Only this media type", Choices: mediaTypeChoices},
+			{Type: discordgo.ApplicationCommandOptionInteger, Name: "count", Description: "Number of assets", MinValue: floatPtr(1), MaxValue: latestMaxCount},
+			privateOption,
+		},
+	}
+}
+
+func handleLatest(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	mediaType, count := "", latestDefaultCount
+	for _, o := range i.ApplicationCommandData().Options {
+		switch o.Name {
+		case "media_type":
+			mediaType = o.StringValue()
+		case "count":
+			count = int(o.IntValue())
+		}
+	}
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	assets, err := client.LatestAssets(latestFetchSize, 0, false)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	var embeds []*discordgo.MessageEmbed
+	for k := range assets {
+		if len(embeds) == count {
+			break
+		}
+		if matchesMediaType(&assets[k], mediaType) {
+			embeds = append(embeds, latestAssetEmbed(i, &assets[k]))
+		}
+	}
+	if len(embeds) == 0 {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_latest"), Description: getTextWithCtx(i, "latest_none"), Color: 0xFFFF00})
+		return
+	}
+	followupEmbeds(s, i, embeds)
+}
+
+// matchesMediaType reports whether the asset's media is of mediaType; an
+// empty mediaType matches everything.
+func matchesMediaType(asset *dto.IPAsset, mediaType string) bool {
+	if mediaType == "" {
+		return true
+	}
+	m := asset.NFTMetadata
+	if m == nil {
+		return false
+	}
+	if strings.Contains(strings.ToLower(m.MediaType), mediaType) {
+		return true
+	}
+	// assets without a mediaType are images when they only have an image
+	return mediaType == "image" && m.MediaType == "" && m.Image != nil && m.Image.OriginalUrl != "" && m.Animation == nil
+}
+
+// latestAssetEmbed is the license preview of a new asset with its image as
+// thumbnail. The thumbnail is left out whenever the moderation verdict asks
+// for caution, as thumbnails can't be put behind a spoiler.
+func latestAssetEmbed(i *discordgo.InteractionCreate, asset *dto.IPAsset) *discordgo.MessageEmbed {
+	embed := previewLicenseEmbed(i, asset)
+	if asset.CreatedAt != nil {
+		embed.Timestamp = asset.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	hide, note := mediaGate(i, asset)
+	if note != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_media"), Value: note})
+	}
+	if !hide && note == "" && asset.NFTMetadata != nil && asset.NFTMetadata.Image != nil && asset.NFTMetadata.Image.OriginalUrl != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: asset.NFTMetadata.Image.OriginalUrl}
+	}
+	return embed
+}
+
+// latestFeed remembers, per network, up to where assets were already seen so
+// each poll posts only newly registered ones.
+type latestFeed struct {
+	marks map[string]*feedMark
+}
+
+// feedMark is the creation time of the newest asset seen and the ids of the
+// assets created at exactly that time, which the next poll sees again.
+type feedMark struct {
+	at  time.Time
+	ids map[string]struct{}
+}
+
+// seen reports whether the asset is at or before the mark.
+func (m *feedMark) seen(asset *dto.IPAsset) bool {
+	if asset.CreatedAt.Before(m.at) {
+		return true
+	}
+	_, ok := m.ids[strings.ToLower(asset.IpId)]
+	return ok && asset.CreatedAt.Equal(m.at)
+}
+
+// advance moves the mark to the newest of assets.
+func (m *feedMark) advance(assets []*dto.IPAsset) {
+	for _, a := range assets {
+		switch {
+		case a.CreatedAt.After(m.at):
+			m.at = *a.CreatedAt
+			m.ids = map[string]struct{}{strings.ToLower(a.IpId): {}}
+		case a.CreatedAt.Equal(m.at):
+			m.ids[strings.ToLower(a.IpId)] = struct{}{}
+		}
+	}
+}
+
+// runLatestFeed polls for new assets and posts them to the feed channels of
+// all guilds that set one, on each guild's default network. The first poll
+// of a network only records what already exists.
+func runLatestFeed(ctx context.Context, s *discordgo.Session) {
+	feed := &latestFeed{marks: map[string]*feedMark{}}
+	ticker := time.NewTicker(feedPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			feed.poll(s)
+		}
+	}
+}
+
+func (f *latestFeed) poll(s *discordgo.Session) {
+	guilds, err := database.ListFeedGuilds()
+	if err != nil {
+		log.Errorf("failed to load feed channels: %v", err)
+		return
+	}
+	byNetwork := map[*network.Network][]models.GuildSettings{}
+	for _, gs := range guilds {
+		n := guildNetwork(gs.GuildID)
+		byNetwork[n] = append(byNetwork[n], gs)
+	}
+	// networks nobody follows start over once a channel is set
+	for name := range f.marks {
+		if n, ok := network.Networks().Lookup(name); !ok || len(byNetwork[n]) == 0 {
+			delete(f.marks, name)
+		}
+	}
+
+	for n, guilds := range byNetwork {
+		fresh, err := f.fresh(n)
+		if err != nil {
+			log.Errorf("failed to poll latest assets on %s: %v", n.Name, err)
+			continue
+		}
+		for _, gs := range guilds {
+			postFeed(s, gs.GuildID, gs.FeedChannelID, gs.FeedMediaType, fresh)
+		}
+	}
+}
+
+// fresh returns the assets of n registered since the last poll, oldest first.
+// It pages back until it reaches the mark of the last poll.
+func (f *latestFeed) fresh(n *network.Network) ([]*dto.IPAsset, error) {
+	mark, primed := f.marks[n.Name]
+	if !primed || mark.at.IsZero() {
+		mark, primed = &feedMark{ids: map[string]struct{}{}}, false
+	}
+	var fresh []*dto.IPAsset
+	// pages shift while assets are registered, so one may repeat
+	got := map[string]struct{}{}
+	reached := false
+	for page := 0; page < feedMaxPages && !reached; page++ {
+		// the public stream only carries assets that passed moderation
+		assets, err := networkClient(n).LatestAssets(latestFetchSize, page*latestFetchSize, true)
+		if err != nil {
+			return nil, err
+		}
+		for k := range assets {
+			a := &assets[k]
+			if a.CreatedAt == nil {
+				continue
+			}
+			if primed && mark.seen(a) {
+				reached = true
+				break
+			}
+			if _, dup := got[strings.ToLower(a.IpId)]; dup {
+				continue
+			}
+			got[strings.ToLower(a.IpId)] = struct{}{}
+			fresh = append(fresh, a)
+		}
+		// the first poll only records the newest page
+		if !primed || len(assets) < latestFetchSize {
+			reached = true
+		}
+	}
+	if !reached {
+		log.Warnf("feed on %s: more than %d assets since the last poll, older ones are skipped", n.Name, feedMaxPages*latestFetchSize)
+	}
+	mark.advance(fresh)
+	f.marks[n.Name] = mark
+	if !primed {
+		return nil, nil
+	}
+	// post oldest first, like a timeline
+	for l, r := 0, len(fresh)-1; l < r; l, r = l+1, r-1 {
+		fresh[l], fresh[r] = fresh[r], fresh[l]
+	}
+	return fresh, nil
+}
+
+func postFeed(s *discordgo.Session, guildID, channelID, mediaType string, assets []*dto.IPAsset) {
+	i := guildInteraction(guildID)
+	i.ChannelID = channelID
+	var embeds []*discordgo.MessageEmbed
+	for _, asset := range assets {
+		if !matchesMediaType(asset, mediaType) {
+			continue
+		}
+		embed := latestAssetEmbed(i, asset)
+		applyEmbedDefaults(i, embed)
+		embeds = append(embeds, embed)
+	}
+	for start := 0; start < len(embeds); start += latestMaxCount {
+		chunk := embeds[start:min(start+latestMaxCount, len(embeds))]
+		if _, err := s.ChannelMessageSendEmbeds(channelID, chunk); err != nil {
+			log.Warnf("failed to post feed to channel %s of guild %s: %v", channelID, guildID, err)
+			return
+		}
+	}
+}
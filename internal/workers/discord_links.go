@@ -0,0 +1,65 @@
+package workers
+
+import (
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/explorer"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+)
+
+// maxRowButtons is Discord's limit of buttons per action row.
+const maxRowButtons = 5
+
+// explorerLinks builds explorer URLs on the network of the interaction.
+func explorerLinks(i *discordgo.InteractionCreate) explorer.Links {
+	return interactionNetwork(i).Explorer
+}
+
+// linkButton is a link button, or nil when url is empty.
+func linkButton(i *discordgo.InteractionCreate, labelKey, url string) discordgo.MessageComponent {
+	if url == "" {
+		return nil
+	}
+	return discordgo.Button{Label: getTextWithCtx(i, labelKey), Style: discordgo.LinkButton, URL: url}
+}
+
+// linkRow puts the non-nil buttons into one row, keeping the first
+// maxRowButtons. It returns nil when there is nothing to link.
+func linkRow(buttons ...discordgo.MessageComponent) discordgo.MessageComponent {
+	var row []discordgo.MessageComponent
+	for _, b := range buttons {
+		if b != nil && len(row) < maxRowButtons {
+			row = append(row, b)
+		}
+	}
+	if len(row) == 0 {
+		return nil
+	}
+	return discordgo.ActionsRow{Components: row}
+}
+
+// assetLinkButtons links the IP page, the owner, the token contract (the
+// token itself when its id is known) and the mint transaction of an asset.
+func assetLinkButtons(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) []discordgo.MessageComponent {
+	links := explorerLinks(i)
+	contract := linkButton(i, "btn_token", links.Token(assetCollectionAddress(asset), asset.TokenId))
+	if contract == nil {
+		contract = linkButton(i, "btn_contract", links.Address(assetCollectionAddress(asset)))
+	}
+	var tx string
+	if m := storyclient.AssetMint(asset); m != nil {
+		tx = links.Tx(m.TransactionHash)
+	}
+	return []discordgo.MessageComponent{
+		linkButton(i, "btn_ip_page", links.IP(ipId)),
+		linkButton(i, "btn_owner", links.Address(asset.OwnerAddress)),
+		contract,
+		linkButton(i, "btn_mint_tx", tx),
+	}
+}
+
+// assetLinkRow is the link row of a license card: Play, when the asset has
+// playable media, followed by the explorer links.
+func assetLinkRow(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId, playLink string) discordgo.MessageComponent {
+	return linkRow(append([]discordgo.MessageComponent{linkButton(i, "btn_play", playLink)}, assetLinkButtons(i, asset, ipId)...)...)
+}
@@ -0,0 +1,82 @@
+package workers
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/network"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+)
+
+var (
+	networkClients   = map[string]*storyclient.Client{}
+	networkClientsMu sync.Mutex
+)
+
+// networkClient returns the shared API client of n.
+func networkClient(n *network.Network) *storyclient.Client {
+	networkClientsMu.Lock()
+	defer networkClientsMu.Unlock()
+	c, ok := networkClients[n.Name]
+	if !ok {
+		c = storyclient.NewNetworkClient(n)
+		networkClients[n.Name] = c
+	}
+	return c
+}
+
+// guildNetwork is the default network of a guild.
+func guildNetwork(guildID string) *network.Network {
+	if n, ok := network.Networks().Lookup(resolveGuildSettings(guildID).Network); ok {
+		return n
+	}
+	return network.Networks().Default()
+}
+
+// interactionNetwork is the network an interaction is about: the command's
+// network option, the network a component was created for, or the guild
+// default.
+func interactionNetwork(i *discordgo.InteractionCreate) *network.Network {
+	if i == nil || i.Interaction == nil {
+		return network.Networks().Default()
+	}
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		if name, ok := stringOption(i.ApplicationCommandData().Options, networkOptionName); ok {
+			if n, ok := network.Networks().Lookup(name); ok {
+				return n
+			}
+		}
+	case discordgo.InteractionMessageComponent:
+		if p, err := decodeComponentID(i.MessageComponentData().CustomID); err == nil {
+			return network.Networks().At(p.Network)
+		}
+	}
+	return guildNetwork(i.GuildID)
+}
+
+// interactionClient is the API client of interactionNetwork.
+func interactionClient(i *discordgo.InteractionCreate) *storyclient.Client {
+	return networkClient(interactionNetwork(i))
+}
+
+// networkOptionName is the name of the option added by networkOption.
+const networkOptionName = "network"
+
+// networkOption lets lookup commands target a network other than the guild
+// default. It is nil when only one network is configured.
+func networkOption() *discordgo.ApplicationCommandOption {
+	nets := network.Networks().All()
+	if len(nets) < 2 {
+		return nil
+	}
+	return &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionString, Name: networkOptionName, Description: "Network to query", Choices: networkChoices()}
+}
+
+func networkChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var out []*discordgo.ApplicationCommandOptionChoice
+	for _, n := range network.Networks().All() {
+		out = append(out, &discordgo.ApplicationCommandOptionChoice{Name: n.Label, Value: n.Name})
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package workers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+)
+
+// stateSession lets render helpers look up channels in the gateway state
+// cache. It is set once the bot session is created.
+var stateSession *discordgo.Session
+
+// isNSFWChannel reports whether channelID is an age-restricted channel, or a
+// thread in one. Unknown channels and DMs count as not NSFW.
+func isNSFWChannel(channelID string) bool {
+	s := stateSession
+	if s == nil || channelID == "" {
+		return false
+	}
+	ch, err := s.State.Channel(channelID)
+	if err != nil {
+		if ch, err = s.Channel(channelID); err != nil {
+			log.Debugf("failed to resolve channel %s: %v", channelID, err)
+			return false
+		}
+	}
+	if ch.IsThread() && ch.ParentID != "" {
+		return isNSFWChannel(ch.ParentID)
+	}
+	return ch.NSFW
+}
+
+// mediaGate decides how an asset's media may be shown where i happened:
+// outside NSFW channels Unsafe media is hidden and Review media gets a
+// spoiler warning. note is empty when nothing needs to be said.
+func mediaGate(i *discordgo.InteractionCreate, asset *dto.IPAsset) (hide bool, note string) {
+	if asset.Moderation == nil {
+		return false, ""
+	}
+	res := evaluateModeration(i, asset.Moderation)
+	if res.Verdict == moderation.Safe || isNSFWChannel(i.ChannelID) {
+		return false, ""
+	}
+	categories := strings.Join(res.Categories(), ", ")
+	if res.Verdict == moderation.Unsafe {
+		return true, fmt.Sprintf(getTextWithCtx(i, "media_hidden"), categories)
+	}
+	return false, "⚠️ ||" + fmt.Sprintf(getTextWithCtx(i, "media_sensitive"), categories) + "||"
+}
@@ -0,0 +1,221 @@
+package workers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/risk"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+// riskSignalsTTL is how long dispute lookups are reused; the license card
+// re-renders on every click and should not query /disputes or /collections
+// each time.
+const riskSignalsTTL = 5 * time.Minute
+
+// riskSignals are the risk inputs that need API calls beyond the asset itself.
+type riskSignals struct {
+	disputes           []risk.DisputeState
+	collectionDisputes int
+	expires            time.Time
+}
+
+// collectionDisputes is a cached dispute count of a collection.
+type collectionDisputes struct {
+	count   int
+	expires time.Time
+}
+
+var (
+	// riskSignalsCache is keyed by network and lowercase IP id,
+	// collectionDisputesCache by network and lowercase collection address;
+	// riskSignalsCacheMu guards both
+	riskSignalsCache        = map[string]*riskSignals{}
+	collectionDisputesCache = map[string]collectionDisputes{}
+	riskSignalsCacheMu      sync.Mutex
+)
+
+var riskLevelBadges = map[risk.Level]string{
+	risk.Low:      "🟢",
+	risk.Medium:   "🟡",
+	risk.High:     "🟠",
+	risk.Critical: "🔴",
+}
+
+func loadRiskSignals(client *storyclient.Client, asset *dto.IPAsset) (*riskSignals, error) {
+	key := client.Network().Name + "/" + strings.ToLower(asset.IpId)
+	riskSignalsCacheMu.Lock()
+	cached, ok := riskSignalsCache[key]
+	riskSignalsCacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached, nil
+	}
+
+	disputes, err := client.GetDisputesByIP(asset.IpId)
+	if err != nil {
+		return nil, err
+	}
+	sig := &riskSignals{expires: time.Now().Add(riskSignalsTTL)}
+	for _, d := range disputes {
+		sig.disputes = append(sig.disputes, risk.ClassifyDispute(d.Status, d.CurrentTag, d.TargetTag))
+	}
+	if address := assetCollectionAddress(asset); address != "" {
+		n, err := loadCollectionDisputes(client, address)
+		if err != nil {
+			return nil, err
+		}
+		sig.collectionDisputes = n
+	}
+
+	riskSignalsCacheMu.Lock()
+	defer riskSignalsCacheMu.Unlock()
+	now := time.Now()
+	for k, s := range riskSignalsCache {
+		if now.After(s.expires) {
+			delete(riskSignalsCache, k)
+		}
+	}
+	riskSignalsCache[key] = sig
+	return sig, nil
+}
+
+// loadCollectionDisputes counts raised and judged disputes of a collection.
+// Counts are cached apart from the asset signals, as every asset of a
+// collection shares them.
+func loadCollectionDisputes(client *storyclient.Client, address string) (int, error) {
+	key := client.Network().Name + "/" + strings.ToLower(address)
+	riskSignalsCacheMu.Lock()
+	cached, ok := collectionDisputesCache[key]
+	riskSignalsCacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.count, nil
+	}
+
+	item, err := client.GetCollectionDisputes(address)
+	if err != nil {
+		return 0, err
+	}
+	entry := collectionDisputes{expires: time.Now().Add(riskSignalsTTL)}
+	if item != nil {
+		entry.count = item.RaisedDisputeCount + item.JudgedDisputeCount
+	}
+
+	riskSignalsCacheMu.Lock()
+	defer riskSignalsCacheMu.Unlock()
+	now := time.Now()
+	for k, c := range collectionDisputesCache {
+		if now.After(c.expires) {
+			delete(collectionDisputesCache, k)
+		}
+	}
+	collectionDisputesCache[key] = entry
+	return entry.count, nil
+}
+
+func assetCollectionAddress(asset *dto.IPAsset) string {
+	if c := storyclient.AssetContract(asset); c != nil && c.Address != "" {
+		return c.Address
+	}
+	return asset.TokenContract
+}
+
+// assessRisk scores an asset for the guild of i; the moderation verdict
+// follows the guild's policy.
+func assessRisk(i *discordgo.InteractionCreate, client *storyclient.Client, asset *dto.IPAsset) (risk.Score, error) {
+	sig, err := loadRiskSignals(client, asset)
+	if err != nil {
+		return risk.Score{}, err
+	}
+	in := risk.Input{
+		InfringementChecked: len(asset.Infringement) > 0,
+		Disputes:            sig.disputes,
+		CollectionDisputes:  sig.collectionDisputes,
+	}
+	if asset.Moderation != nil {
+		in.Moderation = evaluateModeration(i, asset.Moderation).Verdict
+	}
+	in.Infringing = infringementStateOf(asset.Infringement) == infringementFound
+	if asset.Title == "" {
+		in.MissingMetadata = append(in.MissingMetadata, "title")
+	}
+	if asset.Description == "" {
+		in.MissingMetadata = append(in.MissingMetadata, "description")
+	}
+	if m := asset.NFTMetadata; m == nil || ((m.Image == nil || m.Image.OriginalUrl == "") && (m.Animation == nil || m.Animation.OriginalUrl == "")) {
+		in.MissingMetadata = append(in.MissingMetadata, "media")
+	}
+	if lt := storyclient.PrimaryLicense(asset); lt != nil && lt.Terms != nil {
+		in.License = &risk.License{
+			CommercialUse:       lt.Terms.CommercialUse,
+			DerivativesAllowed:  lt.Terms.DerivativesAllowed,
+			DerivativesApproval: lt.Terms.DerivativesApproval,
+			AttributionRequired: lt.Terms.AttributionRequired,
+		}
+	}
+	return risk.Assess(in), nil
+}
+
+// riskBadge is the one-line score shown on the license card.
+func riskBadge(i *discordgo.InteractionCreate, score risk.Score) string {
+	level := score.Level()
+	return fmt.Sprintf("%s %d/100 · %s", riskLevelBadges[level], score.Value, getTextWithCtx(i, "risk_"+strings.ToLower(string(level))))
+}
+
+// riskBreakdown lists the factors of a score in the order they were assessed.
+func riskBreakdown(i *discordgo.InteractionCreate, score risk.Score) string {
+	if len(score.Factors) == 0 {
+		return getTextWithCtx(i, "risk_no_factors")
+	}
+	lines := make([]string, 0, len(score.Factors))
+	for _, f := range score.Factors {
+		text := getTextWithCtx(i, "risk_factor_"+f.Key)
+		if f.Count > 0 {
+			text = fmt.Sprintf(text, f.Count)
+		}
+		lines = append(lines, fmt.Sprintf("`+%2d` %s", f.Points, text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func handleRisk(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId string) {
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	asset, err := fetchAsset(client, ipId)
+	if err != nil {
+		followupAssetError(s, i, err)
+		return
+	}
+	if asset == nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_risk"), Description: getTextWithCtx(i, "not_found"), Color: 0xFFFF00})
+		return
+	}
+	recordLookup(i, models.LookupKindIP, ipId, asset.Title)
+	score, err := assessRisk(i, client, asset)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	title := ipId
+	if asset.Title != "" {
+		title = asset.Title
+	}
+	colors := map[risk.Level]int{risk.Low: 0x00FF00, risk.Medium: 0xFFFF00, risk.High: 0xFFAA00, risk.Critical: 0xFF0000}
+	followupEmbed(s, i, &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_risk"), title),
+		URL:         explorerLinks(i).IP(ipId),
+		Description: riskBadge(i, score),
+		Color:       colors[score.Level()],
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: getTextWithCtx(i, "embed_id"), Value: ipId},
+			{Name: getTextWithCtx(i, "risk_breakdown"), Value: riskBreakdown(i, score)},
+		},
+	})
+}
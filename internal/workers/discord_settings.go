@@ -0,0 +1,447 @@
+package workers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/configs"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultFooterText = "🧩 Powered by Endorphine Stake"
+	// settingsCacheTTL bounds how long settings saved by another bot instance
+	// stay unnoticed; writes on this instance invalidate immediately
+	settingsCacheTTL = time.Minute
+)
+
+// botSettings is the effective configuration of an interaction: env defaults
+// merged with the overrides stored for its guild.
+type botSettings struct {
+	GuildID        string
+	Locale         string // guild override only; empty means user/env locale
+	ButtonTimeout  time.Duration
+	Ephemeral      bool
+	AlertChannelID string
+	FooterText     string
+	EmbedColor     *int   // nil keeps the per-view color
+	ExpiryMode     string // what happens to cards when buttons expire
+	// ModerationPolicy turns moderation likelihoods into verdicts
+	ModerationPolicy moderation.Policy
+	FeedChannelID    string
+	FeedMediaType    string
+	Network          string // default network name, empty for the registry default
+}
+
+// cachedSettings is a settingsCache entry.
+type cachedSettings struct {
+	settings *botSettings
+	expires  time.Time
+}
+
+var (
+	settingsCache   = map[string]cachedSettings{}
+	settingsCacheMu sync.RWMutex
+)
+
+// resolveSettings is the single lookup used by handlers to read guild settings.
+func resolveSettings(i *discordgo.InteractionCreate) *botSettings {
+	guildID := ""
+	if i != nil && i.Interaction != nil {
+		guildID = i.GuildID
+	}
+	return resolveGuildSettings(guildID)
+}
+
+func resolveGuildSettings(guildID string) *botSettings {
+	if guildID != "" {
+		settingsCacheMu.RLock()
+		c, ok := settingsCache[guildID]
+		settingsCacheMu.RUnlock()
+		if ok && time.Now().Before(c.expires) {
+			return c.settings
+		}
+	}
+
+	config := configs.GetEnvConfig()
+	st := &botSettings{
+		GuildID:       guildID,
+		ButtonTimeout: time.Duration(config.STORY_BUTTON_TIMEOUT_SEC) * time.Second,
+		FooterText:    defaultFooterText,
+		ExpiryMode:    models.ExpiryModeDelete,
+		// guilds without a stored policy use the default thresholds
+		ModerationPolicy: moderation.DefaultPolicy(),
+	}
+	if guildID == "" {
+		return st
+	}
+
+	gs, err := database.GetGuildSettings(guildID)
+	if err != nil {
+		// don't cache on failure so the next interaction retries the lookup
+		log.Warnf("failed to load settings for guild %s: %v", guildID, err)
+		return st
+	}
+	if gs != nil {
+		st.Locale = gs.Locale
+		if gs.ButtonTimeoutSec > 0 {
+			st.ButtonTimeout = time.Duration(gs.ButtonTimeoutSec) * time.Second
+		}
+		if gs.Ephemeral != nil {
+			st.Ephemeral = *gs.Ephemeral
+		}
+		st.AlertChannelID = gs.AlertChannelID
+		if gs.FooterText != "" {
+			st.FooterText = gs.FooterText
+		}
+		st.EmbedColor = gs.EmbedColor
+		if gs.ExpiryMode != "" {
+			st.ExpiryMode = gs.ExpiryMode
+		}
+		st.Network = gs.Network
+		st.FeedChannelID = gs.FeedChannelID
+		st.FeedMediaType = gs.FeedMediaType
+		if gs.ModerationPolicy != "" {
+			if p, err := moderation.ParsePolicy(gs.ModerationPolicy); err != nil {
+				log.Warnf("invalid moderation policy for guild %s: %v", guildID, err)
+			} else {
+				st.ModerationPolicy = p
+			}
+		}
+	}
+
+	settingsCacheMu.Lock()
+	settingsCache[guildID] = cachedSettings{settings: st, expires: time.Now().Add(settingsCacheTTL)}
+	settingsCacheMu.Unlock()
+	return st
+}
+
+func invalidateSettings(guildID string) {
+	settingsCacheMu.Lock()
+	delete(settingsCache, guildID)
+	settingsCacheMu.Unlock()
+}
+
+// accentColor returns the guild branding color if configured, else fallback.
+// Status colors (errors, warnings, verdicts) should not go through it.
+func accentColor(i *discordgo.InteractionCreate, fallback int) int {
+	if c := resolveSettings(i).EmbedColor; c != nil {
+		return *c
+	}
+	return fallback
+}
+
+func settingsCommand() *discordgo.ApplicationCommand {
+	perms := int64(discordgo.PermissionManageGuild)
+	dm := false
+	return &discordgo.ApplicationCommand{
+		Name:                     "settings",
+		Description:              "Configure the bot for this server",
+		DefaultMemberPermissions: &perms,
+		DMPermission:             &dm,
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "show", Description: "Show current settings"},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "locale", Description: "Set bot language", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "value", Description: "Language", Required: true, Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "English", Value: "en"},
+				}},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "button_timeout", Description: "Set how long buttons stay active", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "seconds", Description: "Timeout in seconds", Required: true, MinValue: floatPtr(30), MaxValue: 86400},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "ephemeral", Description: "Reply privately by default", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "enabled", Description: "Only the caller sees replies", Required: true},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "alert_channel", Description: "Set channel for bot alerts", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Alert channel", Required: true, ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText}},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "branding", Description: "Set embed footer and color", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "footer", Description: "Footer text", MaxLength: 256},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "color", Description: "Hex color, e.g. #1E90FF"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "reset", Description: "Restore the default footer and colors first"},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "expired_cards", Description: "Choose what happens to cards when buttons expire", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "mode", Description: "Expiry behavior", Required: true, Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Delete message", Value: models.ExpiryModeDelete},
+					{Name: "Offer refresh", Value: models.ExpiryModeRefresh},
+				}},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "auto_preview", Description: "Preview IP IDs posted in a channel", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "enabled", Description: "Enable previews", Required: true},
+				{Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Channel (defaults to current)", ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText}},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "moderation", Description: "Set moderation thresholds of a category", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "category", Description: "Moderation category", Required: true, Choices: moderationCategoryChoices()},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "review_at", Description: "Flag for review at this likelihood or above", Required: true, Choices: likelihoodChoices()},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "block_at", Description: "Mark unsafe at this likelihood or above", Required: true, Choices: likelihoodChoices()},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "feed", Description: "Post newly registered IP assets to a channel", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "enabled", Description: "Enable the feed", Required: true},
+				{Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Channel (defaults to current)", ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText}},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "media_type", Description: "Only post this media type", Choices: mediaTypeChoices},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "network", Description: "Set the network lookups use by default", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "value", Description: "Network", Required: true, Choices: networkChoices()},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "reset", Description: "Reset all settings to defaults"},
+		},
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func moderationCategoryChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var out []*discordgo.ApplicationCommandOptionChoice
+	for _, c := range moderation.Categories {
+		out = append(out, &discordgo.ApplicationCommandOptionChoice{Name: string(c), Value: string(c)})
+	}
+	return out
+}
+
+func likelihoodChoices() []*discordgo.ApplicationCommandOptionChoice {
+	out := []*discordgo.ApplicationCommandOptionChoice{{Name: "Off", Value: "OFF"}}
+	for l := moderation.VeryUnlikely; l <= moderation.VeryLikely; l++ {
+		out = append(out, &discordgo.ApplicationCommandOptionChoice{Name: l.String(), Value: l.String()})
+	}
+	return out
+}
+
+func handleSettings(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" || i.Member == nil {
+		respondEphemeral(s, i, getTextWithCtx(i, "guild_only"))
+		return
+	}
+	// DefaultMemberPermissions can be overridden by guild admins, so check again
+	if i.Member.Permissions&(discordgo.PermissionManageGuild|discordgo.PermissionAdministrator) == 0 {
+		respondEphemeral(s, i, getTextWithCtx(i, "settings_forbidden"))
+		return
+	}
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		respondEphemeral(s, i, getTextWithCtx(i, "missing_param"))
+		return
+	}
+	sub := data.Options[0]
+	opts := map[string]*discordgo.ApplicationCommandInteractionDataOption{}
+	for _, o := range sub.Options {
+		opts[o.Name] = o
+	}
+
+	if sub.Name == "show" {
+		respondSettings(s, i)
+		return
+	}
+	if sub.Name == "auto_preview" {
+		if !configs.GetEnvConfig().AUTO_PREVIEW_ENABLED {
+			respondEphemeral(s, i, getTextWithCtx(i, "auto_preview_unavailable"))
+			return
+		}
+		channelID := i.ChannelID
+		if o, ok := opts["channel"]; ok {
+			channelID = o.ChannelValue(nil).ID
+		}
+		if err := setPreviewChannel(i.GuildID, channelID, i.Member.User.ID, opts["enabled"].BoolValue()); err != nil {
+			logrus.Error("failed to save auto-preview channel: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		respondSettings(s, i)
+		return
+	}
+	if sub.Name == "reset" {
+		if err := database.DeletePreviewChannels(i.GuildID); err != nil {
+			logrus.Error("failed to reset auto-preview channels: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		loadPreviewChannels()
+		if err := database.DeleteGuildSettings(i.GuildID); err != nil {
+			logrus.Error("failed to reset guild settings: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		invalidateSettings(i.GuildID)
+		respondSettings(s, i)
+		return
+	}
+
+	gs, err := database.GetGuildSettings(i.GuildID)
+	if err != nil {
+		logrus.Error("failed to load guild settings: ", err)
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	if gs == nil {
+		gs = &models.GuildSettings{GuildID: i.GuildID}
+	}
+
+	switch sub.Name {
+	case "locale":
+		gs.Locale = opts["value"].StringValue()
+	case "button_timeout":
+		gs.ButtonTimeoutSec = int(opts["seconds"].IntValue())
+	case "ephemeral":
+		v := opts["enabled"].BoolValue()
+		gs.Ephemeral = &v
+	case "expired_cards":
+		gs.ExpiryMode = opts["mode"].StringValue()
+	case "moderation":
+		rule, err := moderation.ParseRule(opts["review_at"].StringValue(), opts["block_at"].StringValue())
+		if err != nil {
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		// start from the effective policy so an unparsable stored one is replaced
+		policy := resolveSettings(i).ModerationPolicy
+		updated := moderation.Policy{}
+		for c, r := range policy {
+			updated[c] = r
+		}
+		updated[moderation.Category(opts["category"].StringValue())] = rule
+		gs.ModerationPolicy = updated.String()
+	case "network":
+		gs.Network = opts["value"].StringValue()
+	case "feed":
+		gs.FeedChannelID, gs.FeedMediaType = "", ""
+		if opts["enabled"].BoolValue() {
+			gs.FeedChannelID = i.ChannelID
+			if o, ok := opts["channel"]; ok {
+				gs.FeedChannelID = o.ChannelValue(nil).ID
+			}
+			if o, ok := opts["media_type"]; ok {
+				gs.FeedMediaType = o.StringValue()
+			}
+		}
+	case "alert_channel":
+		gs.AlertChannelID = opts["channel"].ChannelValue(nil).ID
+	case "branding":
+		if o, ok := opts["reset"]; ok && o.BoolValue() {
+			gs.FooterText, gs.EmbedColor = "", nil
+		}
+		if o, ok := opts["footer"]; ok {
+			gs.FooterText = strings.TrimSpace(o.StringValue())
+		}
+		if o, ok := opts["color"]; ok {
+			c, err := parseHexColor(o.StringValue())
+			if err != nil {
+				respondEphemeral(s, i, getTextWithCtx(i, "settings_invalid_color"))
+				return
+			}
+			gs.EmbedColor = &c
+		}
+	default:
+		respondEphemeral(s, i, getTextWithCtx(i, "unknown_command"))
+		return
+	}
+
+	gs.UpdatedBy = i.Member.User.ID
+	if err := database.SaveGuildSettings(gs); err != nil {
+		logrus.Error("failed to save guild settings: ", err)
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	invalidateSettings(i.GuildID)
+	respondSettings(s, i)
+}
+
+// respondSettings replies with the effective settings of the guild.
+func respondSettings(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	st := resolveSettings(i)
+	locale := st.Locale
+	if locale == "" {
+		locale = "—"
+	}
+	alert := "—"
+	if st.AlertChannelID != "" {
+		alert = "<#" + st.AlertChannelID + ">"
+	}
+	feed := "—"
+	if st.FeedChannelID != "" {
+		feed = "<#" + st.FeedChannelID + ">"
+		if st.FeedMediaType != "" {
+			feed += " · " + st.FeedMediaType
+		}
+	}
+	color := "—"
+	if st.EmbedColor != nil {
+		color = fmt.Sprintf("#%06X", *st.EmbedColor)
+	}
+	yes := map[bool]string{true: "✅", false: "❌"}
+	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_settings"), Color: accentColor(i, 0x5865F2)}
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: getTextWithCtx(i, "settings_locale"), Value: locale, Inline: true},
+		{Name: getTextWithCtx(i, "settings_button_timeout"), Value: st.ButtonTimeout.String(), Inline: true},
+		{Name: getTextWithCtx(i, "settings_ephemeral"), Value: yes[st.Ephemeral], Inline: true},
+		{Name: getTextWithCtx(i, "settings_alert_channel"), Value: alert, Inline: true},
+		{Name: getTextWithCtx(i, "settings_color"), Value: color, Inline: true},
+		{Name: getTextWithCtx(i, "settings_expired_cards"), Value: st.ExpiryMode, Inline: true},
+		{Name: getTextWithCtx(i, "settings_footer"), Value: st.FooterText, Inline: false},
+		{Name: getTextWithCtx(i, "settings_moderation"), Value: moderationPolicyText(st.ModerationPolicy), Inline: false},
+		{Name: getTextWithCtx(i, "settings_feed"), Value: feed, Inline: false},
+		{Name: getTextWithCtx(i, "settings_network"), Value: guildNetwork(i.GuildID).Label, Inline: true},
+	}
+	if configs.GetEnvConfig().AUTO_PREVIEW_ENABLED {
+		previews := "—"
+		if ids, err := database.ListPreviewChannels(i.GuildID); err == nil && len(ids) > 0 {
+			mentions := make([]string, len(ids))
+			for k, id := range ids {
+				mentions[k] = "<#" + id + ">"
+			}
+			previews = strings.Join(mentions, " ")
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "settings_auto_preview"), Value: previews, Inline: false})
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: st.FooterText}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}, Flags: discordgo.MessageFlagsEphemeral},
+	})
+}
+
+// moderationPolicyText lists the thresholds of each category, one per line.
+func moderationPolicyText(p moderation.Policy) string {
+	var lines []string
+	for _, c := range moderation.Categories {
+		r := p[c]
+		review, block := "—", "—"
+		if r.Review != moderation.Unknown {
+			review = "≥ " + r.Review.String()
+		}
+		if r.Block != moderation.Unknown {
+			block = "≥ " + r.Block.String()
+		}
+		lines = append(lines, fmt.Sprintf("`%s` review %s · block %s", c, review, block))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: content, Flags: discordgo.MessageFlagsEphemeral},
+	})
+}
+
+// parseHexColor accepts "#RRGGBB", "0xRRGGBB" or "RRGGBB".
+func parseHexColor(raw string) (int, error) {
+	v := strings.TrimSpace(raw)
+	v = strings.TrimPrefix(v, "#")
+	v = strings.TrimPrefix(strings.ToLower(v), "0x")
+	if len(v) != 6 {
+		return 0, fmt.Errorf("invalid color %q", raw)
+	}
+	n, err := strconv.ParseInt(v, 16, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
@@ -0,0 +1,138 @@
+package workers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	"github.com/sirupsen/logrus"
+)
+
+// sharedResult is an ephemeral result kept around so its Share button can
+// re-post it publicly without fetching again.
+type sharedResult struct {
+	embeds     []*discordgo.MessageEmbed
+	components []discordgo.MessageComponent
+	expires    time.Time
+}
+
+var (
+	sharedResults   = map[string]*sharedResult{}
+	sharedResultsMu sync.Mutex
+)
+
+// storeResult keeps copies of embeds and the link buttons of components until
+// ttl elapses and returns the id to reference them by.
+func storeResult(embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent, ttl time.Duration) (string, error) {
+	b := make([]byte, 9)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	res := &sharedResult{components: linkButtonRows(components), expires: time.Now().Add(ttl)}
+	for _, e := range embeds {
+		cp := *e
+		res.embeds = append(res.embeds, &cp)
+	}
+
+	sharedResultsMu.Lock()
+	defer sharedResultsMu.Unlock()
+	now := time.Now()
+	for k, r := range sharedResults {
+		if now.After(r.expires) {
+			delete(sharedResults, k)
+		}
+	}
+	sharedResults[id] = res
+	return id, nil
+}
+
+// takeResult removes and returns a stored result, nil when unknown or expired.
+func takeResult(id string) *sharedResult {
+	sharedResultsMu.Lock()
+	defer sharedResultsMu.Unlock()
+	res, ok := sharedResults[id]
+	if !ok {
+		return nil
+	}
+	delete(sharedResults, id)
+	if time.Now().After(res.expires) {
+		return nil
+	}
+	return res
+}
+
+// linkButtonRows keeps only link buttons, the interactive ones are scoped to
+// the original user and would be useless in a shared copy.
+func linkButtonRows(components []discordgo.MessageComponent) []discordgo.MessageComponent {
+	var out []discordgo.MessageComponent
+	for _, c := range components {
+		ar, ok := c.(discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		var links []discordgo.MessageComponent
+		for _, rc := range ar.Components {
+			if b, ok := rc.(discordgo.Button); ok && b.Style == discordgo.LinkButton {
+				links = append(links, b)
+			}
+		}
+		if len(links) > 0 {
+			out = append(out, discordgo.ActionsRow{Components: links})
+		}
+	}
+	return out
+}
+
+// withShareRow appends a Share button for an ephemeral result. Components are
+// returned unchanged when there is no user or the row limit is reached.
+func withShareRow(i *discordgo.InteractionCreate, embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent) []discordgo.MessageComponent {
+	if interactionUserID(i) == "" || len(components) >= 5 {
+		return components
+	}
+	ttl := resolveSettings(i).ButtonTimeout
+	id, err := storeResult(embeds, components, ttl)
+	if err != nil {
+		logrus.Error("failed to store result for sharing: ", err)
+		return components
+	}
+	cid := encodeComponentID(customid.Payload{
+		Kind:    customid.KindResult,
+		Action:  customid.ActionShare,
+		Target:  id,
+		Owner:   interactionUserID(i),
+		Expires: time.Now().Add(ttl),
+	})
+	if cid == "" {
+		return components
+	}
+	return append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{Label: getTextWithCtx(i, "btn_share"), Style: discordgo.SecondaryButton, CustomID: cid},
+	}})
+}
+
+// handleShareResult re-posts a stored ephemeral result publicly in the channel.
+func handleShareResult(s *discordgo.Session, i *discordgo.InteractionCreate, id string) {
+	res := takeResult(id)
+	if res == nil {
+		respondEphemeral(s, i, getTextWithCtx(i, "component_expired"))
+		return
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf(getTextWithCtx(i, "shared_by"), interactionUserID(i)),
+			Embeds:          res.embeds,
+			Components:      res.components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		logrus.Error("failed to share result: ", err)
+	}
+}
@@ -0,0 +1,202 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	"github.com/goldsheva/discord-story-bot/internal/network"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/goldsheva/discord-story-bot/internal/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	trendingCommand = "trending"
+	// trendingPoolSize is how many top assets are fetched and paged through
+	trendingPoolSize = 200
+	trendingPageSize = 10
+
+	// descendant counts are snapshotted at most once per interval and kept
+	// for the retention period
+	descendantSnapshotInterval  = 24 * time.Hour
+	descendantSnapshotRetention = 30 * 24 * time.Hour
+	trendingDeltaWindow         = 7 * 24 * time.Hour
+)
+
+func trendingCommandDef() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        trendingCommand,
+		Description: "List the most remixed IP assets, optionally of one owner (no collection filter in the API)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "owner", Description: "Only assets of this owner address"},
+			privateOption,
+		},
+	}
+}
+
+func handleTrending(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	owner, _ := stringOption(i.ApplicationCommandData().Options, "owner")
+	owner = strings.TrimSpace(owner)
+	if owner != "" && !wallet.IsAddress(owner) {
+		respondEphemeral(s, i, getTextWithCtx(i, "invalid_address"))
+		return
+	}
+
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	embed, components, err := renderTrending(i, client, owner, 0)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	if len(components) == 0 {
+		followupEmbed(s, i, embed)
+		return
+	}
+	msg, err := followupEmbedWithComponents(s, i, embed, components)
+	if err == nil && msg != nil {
+		scheduleExpiry(i, msg, models.ExpiryKindTrending, owner)
+	}
+}
+
+// renderTrending renders one page of the most remixed assets, of owner only
+// when it is set.
+func renderTrending(i *discordgo.InteractionCreate, client *storyclient.Client, owner string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	assets, err := client.TopAssetsByDescendants(owner, trendingPoolSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	recordDescendants(client.Network().Name, assets)
+
+	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_trending"), Color: accentColor(i, 0xFF8800)}
+	if owner != "" {
+		embed.Description = fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_owner"), owner)
+	}
+	if len(assets) == 0 {
+		embed.Description = strings.TrimSpace(embed.Description + "\n" + getTextWithCtx(i, "trending_none"))
+		embed.Color = 0xFFFF00
+		return embed, nil, nil
+	}
+
+	pages := (len(assets) + trendingPageSize - 1) / trendingPageSize
+	page = max(0, min(page, pages-1))
+	rows := assets[page*trendingPageSize : min((page+1)*trendingPageSize, len(assets))]
+
+	ids := make([]string, len(rows))
+	for k := range rows {
+		ids[k] = strings.ToLower(rows[k].IpId)
+	}
+	before, err := database.DescendantCountsAt(client.Network().Name, ids, time.Now().Add(-trendingDeltaWindow))
+	if err != nil {
+		// the list is still useful without deltas
+		log.Warnf("failed to load descendant snapshots: %v", err)
+		before = map[string]int{}
+	}
+	for k := range rows {
+		n := page*trendingPageSize + k + 1
+		prev, ok := before[ids[k]]
+		embed.Fields = append(embed.Fields, trendingField(i, n, &rows[k], prev, ok))
+	}
+	if pages > 1 {
+		embed.Description = strings.TrimSpace(embed.Description + "\n" + fmt.Sprintf(getTextWithCtx(i, "page_of"), page+1, pages))
+	}
+
+	if pages == 1 || interactionUserID(i) == "" {
+		return embed, nil, nil
+	}
+	return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{Label: getTextWithCtx(i, "btn_prev"), Style: discordgo.SecondaryButton, Disabled: page == 0,
+			CustomID: componentIDArg(i, customid.KindTrending, customid.ActionShow, owner, uint32(max(page-1, 0)))},
+		discordgo.Button{Label: getTextWithCtx(i, "btn_next"), Style: discordgo.SecondaryButton, Disabled: page == pages-1,
+			CustomID: componentIDArg(i, customid.KindTrending, customid.ActionShow, owner, uint32(page+1))},
+	}}}, nil
+}
+
+// trendingField shows an asset's descendant count, its change over the delta
+// window when a snapshot that old exists, and a license summary.
+func trendingField(i *discordgo.InteractionCreate, n int, asset *dto.IPAsset, prev int, hasPrev bool) *discordgo.MessageEmbedField {
+	title := asset.Title
+	if title == "" {
+		title = asset.IpId
+	}
+	delta := "—"
+	if hasPrev {
+		switch d := asset.DescendantCount - prev; {
+		case d > 0:
+			delta = "▲ +" + strconv.Itoa(d)
+		case d < 0:
+			delta = "▼ " + strconv.Itoa(d)
+		default:
+			delta = "±0"
+		}
+	}
+	lines := []string{
+		fmt.Sprintf(getTextWithCtx(i, "trending_counts"), asset.DescendantCount, delta, asset.ChildrenCount),
+	}
+	if lt := storyclient.PrimaryLicense(asset); lt != nil && lt.Terms != nil {
+		yes := map[bool]string{true: "✅", false: "❌"}
+		lines = append(lines, fmt.Sprintf("%s %s · %s %s · %s %s",
+			getTextWithCtx(i, "embed_commercial_use"), yes[lt.Terms.CommercialUse],
+			getTextWithCtx(i, "embed_derivatives_allowed"), yes[lt.Terms.DerivativesAllowed],
+			getTextWithCtx(i, "embed_commercial_rev_share"), formatRevSharePercent(lt.Terms.CommercialRevShare)))
+	} else {
+		lines = append(lines, getTextWithCtx(i, "no_terms"))
+	}
+	if url := explorerLinks(i).IP(asset.IpId); url != "" {
+		lines = append(lines, fmt.Sprintf("[`%s`](%s)", asset.IpId, url))
+	} else {
+		lines = append(lines, "`"+asset.IpId+"`")
+	}
+	return &discordgo.MessageEmbedField{Name: truncateRunes(fmt.Sprintf("%d. %s", n, title), 256), Value: strings.Join(lines, "\n")}
+}
+
+// recordDescendants snapshots the descendant counts of assets on the named network.
+func recordDescendants(networkName string, assets []dto.IPAsset) {
+	counts := make(map[string]int, len(assets))
+	for k := range assets {
+		counts[strings.ToLower(assets[k].IpId)] = assets[k].DescendantCount
+	}
+	if err := database.SaveDescendantSnapshots(networkName, counts, descendantSnapshotInterval); err != nil {
+		log.Warnf("failed to save descendant snapshots: %v", err)
+	}
+}
+
+// runDescendantSnapshots records the counts of the top assets of every
+// network on startup and then once per interval, so weekly deltas exist even
+// for lists nobody opened.
+func runDescendantSnapshots(ctx context.Context) {
+	snapshot := func() {
+		for _, n := range network.Networks().All() {
+			assets, err := networkClient(n).TopAssetsByDescendants("", trendingPoolSize)
+			if err != nil {
+				log.Warnf("failed to fetch top assets on %s: %v", n.Name, err)
+				continue
+			}
+			recordDescendants(n.Name, assets)
+		}
+		if err := database.PruneDescendantSnapshots(time.Now().Add(-descendantSnapshotRetention)); err != nil {
+			log.Warnf("failed to prune descendant snapshots: %v", err)
+		}
+	}
+	snapshot()
+	ticker := time.NewTicker(descendantSnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot()
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package workers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/customid"
+	dto "github.com/goldsheva/discord-story-bot/internal/dto"
+	"github.com/goldsheva/discord-story-bot/internal/moderation"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	viewOverview     = "overview"
+	viewTerms        = "terms"
+	viewInfringement = "infringement"
+	viewModeration   = "moderation"
+	viewMint         = "mint"
+	viewCollection   = "collection"
+)
+
+// licenseView is one page of the license card. Views register themselves and
+// are offered in the card's select menu when available for the asset.
+type licenseView struct {
+	Key       string
+	LabelKey  string // i18n key of the select option label
+	TitleKey  string // i18n key of the title used when the view is empty
+	EmptyKey  string // i18n key of the message used when the view is empty
+	Available func(asset *dto.IPAsset) bool
+	Render    func(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent)
+	// RenderPage, when set, replaces Render for views that page through
+	// long lists. Page 0 is the first page.
+	RenderPage func(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent)
+}
+
+var licenseViews []*licenseView
+
+func registerLicenseView(v *licenseView) {
+	licenseViews = append(licenseViews, v)
+}
+
+func (v *licenseView) render(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	if v.RenderPage != nil {
+		return v.RenderPage(i, asset, ipId, page)
+	}
+	return v.Render(i, asset, ipId)
+}
+
+func licenseViewByKey(key string) *licenseView {
+	for _, v := range licenseViews {
+		if v.Key == key {
+			return v
+		}
+	}
+	return nil
+}
+
+// licenseActionViews maps the per-view buttons of older cards and the Refresh
+// button to the view they open. Paged views reuse their action with the page
+// as the custom ID argument.
+var licenseActionViews = map[customid.Action]string{
+	customid.ActionTerms:        viewTerms,
+	customid.ActionInfringement: viewInfringement,
+	customid.ActionModeration:   viewModeration,
+	customid.ActionMint:         viewMint,
+	customid.ActionCollection:   viewCollection,
+	customid.ActionRefresh:      viewOverview,
+}
+
+func init() {
+	always := func(*dto.IPAsset) bool { return true }
+	registerLicenseView(&licenseView{Key: viewOverview, LabelKey: "btn_overview", TitleKey: "title_license", EmptyKey: "not_found", Available: always, Render: renderOverviewView})
+	registerLicenseView(&licenseView{Key: viewTerms, LabelKey: "btn_terms", TitleKey: "title_terms", EmptyKey: "no_terms", Render: renderTermsView,
+		Available: func(a *dto.IPAsset) bool { return storyclient.PrimaryLicense(a) != nil }})
+	registerLicenseView(&licenseView{Key: viewInfringement, LabelKey: "btn_infringement", TitleKey: "title_infringement", EmptyKey: "no_checks", RenderPage: renderInfringementView,
+		Available: func(a *dto.IPAsset) bool { return len(a.Infringement) > 0 }})
+	registerLicenseView(&licenseView{Key: viewModeration, LabelKey: "btn_moderation", TitleKey: "title_moderation", EmptyKey: "no_moderation", Render: renderModerationView,
+		Available: func(a *dto.IPAsset) bool { return a.Moderation != nil }})
+	registerLicenseView(&licenseView{Key: viewMint, LabelKey: "btn_mint", TitleKey: "title_mint", EmptyKey: "no_mint", Render: renderMintView,
+		Available: func(a *dto.IPAsset) bool { return storyclient.AssetMint(a) != nil }})
+	// collection always renders; a missing collection name is shown as ❌
+	registerLicenseView(&licenseView{Key: viewCollection, LabelKey: "btn_collection", TitleKey: "title_collection", EmptyKey: "no_collection", Available: always, Render: renderLicenseCollectionView})
+}
+
+// renderLicenseCard renders the given view of the card together with its
+// components: the link row (Play and explorer links), view-specific rows,
+// the navigation menu and the Export menu. The overview carries the risk badge.
+func renderLicenseCard(i *discordgo.InteractionCreate, client *storyclient.Client, asset *dto.IPAsset, ipId, key string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	v := licenseViewByKey(key)
+	if v == nil || !v.Available(asset) {
+		v = licenseViewByKey(viewOverview)
+	}
+	embed, viewComps := v.render(i, asset, ipId, page)
+	if v.Key == viewOverview {
+		if score, err := assessRisk(i, client, asset); err != nil {
+			logrus.Warnf("risk assessment failed for %s: %v", ipId, err)
+		} else {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "title_risk"), Value: riskBadge(i, score), Inline: true})
+		}
+	}
+
+	var components []discordgo.MessageComponent
+	_, playLink := buildLicenseEmbed(i, asset, ipId)
+	if row := assetLinkRow(i, asset, ipId, playLink); row != nil {
+		components = append(components, row)
+	}
+	components = append(components, viewComps...)
+	if nav := licenseNavRow(i, asset, ipId, v.Key); nav != nil {
+		components = append(components, nav)
+	}
+	return embed, append(components, exportRow(i, customid.KindLicense, ipId, 0))
+}
+
+// licenseNavRow is the select menu listing views available for the asset,
+// scoped to the interaction user.
+func licenseNavRow(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId, current string) discordgo.MessageComponent {
+	if interactionUserID(i) == "" {
+		return nil
+	}
+	var options []discordgo.SelectMenuOption
+	for _, v := range licenseViews {
+		if !v.Available(asset) {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{Label: getTextWithCtx(i, v.LabelKey), Value: v.Key, Default: v.Key == current})
+	}
+	return discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.SelectMenu{
+			MenuType:    discordgo.StringSelectMenu,
+			CustomID:    componentID(i, customid.KindLicense, customid.ActionView, ipId),
+			Placeholder: getTextWithCtx(i, "select_view"),
+			Options:     options,
+		},
+	}}
+}
+
+func renderOverviewView(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed, _ := buildLicenseEmbed(i, asset, ipId)
+	return embed, nil
+}
+
+func renderTermsView(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	t := storyclient.PrimaryLicense(asset)
+	if t == nil {
+		return emptyViewEmbed(i, viewTerms), nil
+	}
+	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_terms"), Color: accentColor(i, 0x00AAFF)}
+	// Template fields split per spec
+	if t.LicenseTemplateId != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_template"), Value: t.LicenseTemplateId, Inline: false})
+	}
+	if t.TemplateName != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_template_name"), Value: strings.ToUpper(t.TemplateName), Inline: false})
+	}
+	if t.TemplateMetadataUri != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_template_url"), Value: t.TemplateMetadataUri, Inline: false})
+	}
+	if t.Terms != nil {
+		yes := map[bool]string{true: "✅", false: "❌"}
+		// Render with License first line, then a clean list
+		lines := []string{
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_id"), ipId),
+			"",
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_transferable"), yes[t.Terms.Transferable]),
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_commercial_use"), yes[t.Terms.CommercialUse]),
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_derivatives_allowed"), yes[t.Terms.DerivativesAllowed]),
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_derivatives_approval"), yes[t.Terms.DerivativesApproval]),
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_commercial_rev_share"), formatRevSharePercent(t.Terms.CommercialRevShare)),
+			fmt.Sprintf("%s: %s", getTextWithCtx(i, "embed_attribution"), yes[t.Terms.AttributionRequired]),
+		}
+		embed.Description = strings.Join(lines, "\n")
+	}
+	return embed, nil
+}
+
+// infringementPageSize is how many checks one page of the timeline shows.
+const infringementPageSize = 5
+
+// renderInfringementView lists every infringement check, oldest first, with a
+// per-provider summary on top and Prev/Next buttons for long histories.
+func renderInfringementView(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	arr := sortedInfringement(asset.Infringement)
+	if len(arr) == 0 {
+		return emptyViewEmbed(i, viewInfringement), nil
+	}
+	pages := (len(arr) + infringementPageSize - 1) / infringementPageSize
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+
+	status, color := infringementStatus(arr)
+	lines := []string{status, ""}
+	lines = append(lines, infringementProviderSummary(i, arr)...)
+	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_infringement"), ipId), Color: color}
+
+	from := page * infringementPageSize
+	to := from + infringementPageSize
+	if to > len(arr) {
+		to = len(arr)
+	}
+	for k, it := range arr[from:to] {
+		embed.Fields = append(embed.Fields, infringementField(i, from+k+1, it))
+	}
+	if pages > 1 {
+		lines = append(lines, "", fmt.Sprintf(getTextWithCtx(i, "page_of"), page+1, pages))
+	}
+	embed.Description = strings.Join(lines, "\n")
+
+	if pages == 1 || interactionUserID(i) == "" {
+		return embed, nil
+	}
+	return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{Label: getTextWithCtx(i, "btn_prev"), Style: discordgo.SecondaryButton, Disabled: page == 0,
+			CustomID: componentIDArg(i, customid.KindLicense, customid.ActionInfringement, ipId, uint32(max(page-1, 0)))},
+		discordgo.Button{Label: getTextWithCtx(i, "btn_next"), Style: discordgo.SecondaryButton, Disabled: page == pages-1,
+			CustomID: componentIDArg(i, customid.KindLicense, customid.ActionInfringement, ipId, uint32(page+1))},
+	}}}
+}
+
+func infringementField(i *discordgo.InteractionCreate, n int, it dto.InfringementStatus) *discordgo.MessageEmbedField {
+	provider := it.ProviderName
+	if provider == "" {
+		provider = "?"
+	}
+	name := fmt.Sprintf("#%d · %s", n, provider)
+	if t := infringementTime(it); t != nil {
+		name += " · " + t.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	flag := "✅"
+	if it.IsInfringing {
+		flag = "❌"
+	}
+	lines := []string{fmt.Sprintf("%s %s: %s", flag, getTextWithCtx(i, "embed_status"), it.Status)}
+	if it.InfringementDetails != "" {
+		lines = append(lines, truncateRunes(it.InfringementDetails, 300))
+	}
+	if it.ProviderURL != "" {
+		lines = append(lines, it.ProviderURL)
+	}
+	return &discordgo.MessageEmbedField{Name: truncateRunes(name, 256), Value: truncateRunes(strings.Join(lines, "\n"), 1024)}
+}
+
+// infringementProviderSummary has one line per provider: how many checks it
+// ran and whether it flagged the IP, and if so whether it later cleared it.
+func infringementProviderSummary(i *discordgo.InteractionCreate, arr []dto.InfringementStatus) []string {
+	type summary struct {
+		checks  int
+		flagged bool
+		latest  dto.InfringementStatus
+	}
+	var order []string
+	byProvider := map[string]*summary{}
+	for _, it := range arr {
+		sum, ok := byProvider[it.ProviderName]
+		if !ok {
+			sum = &summary{}
+			byProvider[it.ProviderName] = sum
+			order = append(order, it.ProviderName)
+		}
+		sum.checks++
+		sum.flagged = sum.flagged || it.IsInfringing
+		sum.latest = it
+	}
+	lines := make([]string, 0, len(order))
+	for _, name := range order {
+		sum := byProvider[name]
+		state := getTextWithCtx(i, "infringement_clean")
+		switch {
+		case sum.latest.IsInfringing:
+			state = getTextWithCtx(i, "infringement_flagged")
+		case sum.flagged:
+			state = getTextWithCtx(i, "infringement_cleared")
+		}
+		if name == "" {
+			name = "?"
+		}
+		lines = append(lines, fmt.Sprintf("**%s** — %s (%d)", name, state, sum.checks))
+	}
+	return lines
+}
+
+// sortedInfringement returns a copy of the checks ordered by response time,
+// oldest first. Checks without a time keep their API order at the end.
+func sortedInfringement(arr []dto.InfringementStatus) []dto.InfringementStatus {
+	out := append([]dto.InfringementStatus(nil), arr...)
+	sort.SliceStable(out, func(a, b int) bool {
+		ta, tb := infringementTime(out[a]), infringementTime(out[b])
+		if ta == nil || tb == nil {
+			return ta != nil
+		}
+		return ta.Before(*tb)
+	})
+	return out
+}
+
+func infringementTime(it dto.InfringementStatus) *time.Time {
+	if it.ResponseTime != nil {
+		return it.ResponseTime
+	}
+	return it.CreatedAt
+}
+
+// infringementState is the summarized outcome of the infringement checks of
+// an IP; the zero value means it was never checked.
+type infringementState int
+
+const (
+	infringementUnchecked infringementState = iota
+	infringementClear
+	infringementPending
+	infringementFound
+)
+
+// infringementStateOf summarizes infringement checks. Only the latest check of
+// each provider counts, so an IP that was flagged and later cleared is no
+// longer reported as infringing.
+func infringementStateOf(arr []dto.InfringementStatus) infringementState {
+	latest := map[string]dto.InfringementStatus{}
+	for _, it := range sortedInfringement(arr) {
+		latest[it.ProviderName] = it
+	}
+	state := infringementClear
+	for _, it := range latest {
+		if it.IsInfringing {
+			return infringementFound
+		}
+		if strings.ToLower(it.Status) != "succeeded" {
+			state = infringementPending
+		}
+	}
+	return state
+}
+
+// infringementStatus summarizes infringement checks into a label and color.
+func infringementStatus(arr []dto.InfringementStatus) (string, int) {
+	switch infringementStateOf(arr) {
+	case infringementFound:
+		return "❌ Infringing", 0xFF0000
+	case infringementPending:
+		return "⚠️ Potential issue", 0xFFAA00
+	}
+	return "✅ Not infringing", 0x00FF00
+}
+
+func renderModerationView(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	m := asset.Moderation
+	if m == nil {
+		return emptyViewEmbed(i, viewModeration), nil
+	}
+	// evaluate
+	res := evaluateModeration(i, m)
+	color := 0x00FF00
+	if res.Verdict == moderation.Unsafe {
+		color = 0xFF0000
+	} else if res.Verdict == moderation.Review {
+		color = 0xFFAA00
+	}
+	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_moderation"), ipId), Color: color, Description: moderationSummary(res)}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "adult"), Value: m.Adult, Inline: true})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "spoof"), Value: m.Spoof, Inline: true})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "medical"), Value: m.Medical, Inline: true})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "violence"), Value: m.Violence, Inline: true})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "racy"), Value: m.Racy, Inline: true})
+	return embed, nil
+}
+
+func renderMintView(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	m := storyclient.AssetMint(asset)
+	if m == nil {
+		return emptyViewEmbed(i, viewMint), nil
+	}
+	embed := &discordgo.MessageEmbed{Title: fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_mint"), ipId), Color: accentColor(i, 0x0099FF), URL: explorerLinks(i).Tx(m.TransactionHash)}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "mint_address"), Value: m.MintAddress, Inline: false})
+	if m.BlockNumber != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "block_number"), Value: m.BlockNumber.String(), Inline: true})
+	}
+	if m.Timestamp != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "mint_timestamp"), Value: m.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"), Inline: true})
+	}
+	if m.TransactionHash != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "transaction"), Value: m.TransactionHash, Inline: false})
+	}
+	if m.OwnerAddress != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_owner"), Value: m.OwnerAddress, Inline: true})
+	}
+	if m.LastUpdatedAt != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "last_updated_metadata"), Value: m.LastUpdatedAt.UTC().Format(time.RFC3339), Inline: true})
+	}
+	if m.TimeLastUpdated != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "last_updated_system"), Value: m.TimeLastUpdated.UTC().Format(time.RFC3339), Inline: true})
+	}
+	return embed, nil
+}
+
+func renderLicenseCollectionView(i *discordgo.InteractionCreate, asset *dto.IPAsset, ipId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{Title: getTextWithCtx(i, "title_collection"), Color: accentColor(i, 0x00CC99)}
+	// Show License ID first
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "embed_id"), Value: ipId, Inline: false})
+	contract := storyclient.AssetContract(asset)
+	collection := storyclient.AssetCollection(asset)
+	if contract != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "contract_name"), Value: contract.Name, Inline: true})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "symbol"), Value: contract.Symbol, Inline: true})
+		if contract.Address != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "contract_address"), Value: contract.Address, Inline: false})
+		}
+		if contract.TotalSupply != nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "total_supply"), Value: contract.TotalSupply.String() + " NFTs", Inline: true})
+		}
+	}
+	// Collection name can be absent (one-off NFT). Always show the field per spec.
+	if collection != nil && collection.Name != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "collection_name"), Value: collection.Name, Inline: false})
+	} else {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: getTextWithCtx(i, "collection_name"), Value: "❌", Inline: false})
+	}
+	// add button to open full collection view, if we know the contract address and have user id
+	if interactionUserID(i) != "" && contract != nil && contract.Address != "" {
+		return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: getTextWithCtx(i, "btn_view_collection"), Style: discordgo.SecondaryButton, CustomID: componentID(i, customid.KindCollection, customid.ActionShow, contract.Address)},
+		}}}
+	}
+	return embed, nil
+}
+
+func emptyViewEmbed(i *discordgo.InteractionCreate, key string) *discordgo.MessageEmbed {
+	v := licenseViewByKey(key)
+	return &discordgo.MessageEmbed{Title: getTextWithCtx(i, v.TitleKey), Description: getTextWithCtx(i, v.EmptyKey), Color: 0xFFFF00}
+}
+
+// handleLicenseView fetches the asset and posts one view of it as a new message.
+func handleLicenseView(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client, ipId, key string) {
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error(err)
+		return
+	}
+	asset, err := fetchAsset(client, ipId)
+	if err != nil {
+		followupAssetError(s, i, err)
+		return
+	}
+	if asset == nil {
+		followupEmbed(s, i, emptyViewEmbed(i, key))
+		return
+	}
+	embed, comps := licenseViewByKey(key).render(i, asset, ipId, 0)
+	if row := assetLinkRow(i, asset, ipId, ""); row != nil {
+		comps = append([]discordgo.MessageComponent{row}, comps...)
+	}
+	comps = append(comps, exportRow(i, customid.KindLicense, ipId, 0))
+	_, _ = followupEmbedWithComponents(s, i, embed, comps)
+}
@@ -0,0 +1,200 @@
+package workers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/goldsheva/discord-story-bot/internal/database"
+	"github.com/goldsheva/discord-story-bot/internal/models"
+	storyclient "github.com/goldsheva/discord-story-bot/internal/story"
+	"github.com/goldsheva/discord-story-bot/internal/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	showPortfolioCommand = "Show IP portfolio"
+	walletNonceTTL       = 15 * time.Minute
+	portfolioLimit       = 15
+)
+
+func walletCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "wallet",
+		Description: "Link a wallet to your Discord account",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "link", Description: "Start linking a wallet", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "address", Description: "Wallet address", Required: true},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "verify", Description: "Finish linking with a signed message", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "signature", Description: "Signature of the link message", Required: true},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "show", Description: "Show your linked wallet"},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "unlink", Description: "Remove your linked wallet"},
+		},
+	}
+}
+
+func showPortfolioUserCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{Name: showPortfolioCommand, Type: discordgo.UserApplicationCommand}
+}
+
+func handleWallet(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	uid := interactionUserID(i)
+	data := i.ApplicationCommandData()
+	if uid == "" || len(data.Options) == 0 {
+		respondEphemeral(s, i, getTextWithCtx(i, "missing_param"))
+		return
+	}
+	sub := data.Options[0]
+
+	switch sub.Name {
+	case "link":
+		address, _ := stringOption(sub.Options, "address")
+		address = strings.TrimSpace(address)
+		if !wallet.IsAddress(address) {
+			respondEphemeral(s, i, getTextWithCtx(i, "wallet_invalid_address"))
+			return
+		}
+		nonce, err := wallet.NewNonce()
+		if err != nil {
+			logrus.Error("failed to generate wallet nonce: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		wl, err := database.GetWalletLink(uid)
+		if err != nil {
+			logrus.Error("failed to load wallet link: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		if wl == nil {
+			wl = &models.WalletLink{UserID: uid}
+		}
+		// an already verified wallet stays linked until the new one is verified
+		wl.PendingAddress = strings.ToLower(address)
+		wl.Nonce = nonce
+		wl.NonceExpiresAt = time.Now().UTC().Add(walletNonceTTL)
+		if err := database.SaveWalletLink(wl); err != nil {
+			logrus.Error("failed to save wallet link: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		msg := wallet.LinkMessage(uid, wl.PendingAddress, nonce)
+		respondEphemeral(s, i, fmt.Sprintf("%s\n```\n%s\n```", getTextWithCtx(i, "wallet_sign_instructions"), msg))
+	case "verify":
+		wl, err := database.GetWalletLink(uid)
+		if err != nil {
+			logrus.Error("failed to load wallet link: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		if wl == nil || wl.Nonce == "" || wl.PendingAddress == "" || time.Now().UTC().After(wl.NonceExpiresAt) {
+			respondEphemeral(s, i, getTextWithCtx(i, "wallet_no_pending"))
+			return
+		}
+		signature, _ := stringOption(sub.Options, "signature")
+		ok, err := wallet.Verify(wl.PendingAddress, wallet.LinkMessage(uid, wl.PendingAddress, wl.Nonce), signature)
+		if err != nil || !ok {
+			respondEphemeral(s, i, getTextWithCtx(i, "wallet_bad_signature"))
+			return
+		}
+		now := time.Now().UTC()
+		wl.Address = wl.PendingAddress
+		wl.Verified = true
+		wl.VerifiedAt = &now
+		// a nonce is single use
+		wl.PendingAddress = ""
+		wl.Nonce = ""
+		if err := database.SaveWalletLink(wl); err != nil {
+			logrus.Error("failed to save wallet link: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		respondEphemeral(s, i, fmt.Sprintf("%s `%s`", getTextWithCtx(i, "wallet_linked"), wl.Address))
+	case "show":
+		wl, err := database.GetWalletLink(uid)
+		if err != nil {
+			logrus.Error("failed to load wallet link: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		if wl == nil || !wl.Verified {
+			respondEphemeral(s, i, getTextWithCtx(i, "wallet_not_linked"))
+			return
+		}
+		respondEphemeral(s, i, fmt.Sprintf("%s `%s`", getTextWithCtx(i, "wallet_linked"), wl.Address))
+	case "unlink":
+		if err := database.DeleteWalletLink(uid); err != nil {
+			logrus.Error("failed to delete wallet link: ", err)
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		respondEphemeral(s, i, getTextWithCtx(i, "wallet_unlinked"))
+	default:
+		respondEphemeral(s, i, getTextWithCtx(i, "unknown_command"))
+	}
+}
+
+// handleShowPortfolio lists IP assets owned by the target member's verified wallet.
+func handleShowPortfolio(s *discordgo.Session, i *discordgo.InteractionCreate, client *storyclient.Client) {
+	data := i.ApplicationCommandData()
+	var target *discordgo.User
+	if data.Resolved != nil {
+		target = data.Resolved.Users[data.TargetID]
+	}
+	if target == nil {
+		respondEphemeral(s, i, getTextWithCtx(i, "invalid_component"))
+		return
+	}
+	wl, err := database.GetWalletLink(target.ID)
+	if err != nil {
+		logrus.Error("failed to load wallet link: ", err)
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	if wl == nil || !wl.Verified {
+		respondEphemeral(s, i, fmt.Sprintf(getTextWithCtx(i, "wallet_member_not_linked"), target.Username))
+		return
+	}
+
+	if err := respondDeferred(s, i); err != nil {
+		logrus.Error("defer failed: ", err)
+		return
+	}
+	assets, err := client.GetAssetsByOwner(wl.Address, portfolioLimit, 0)
+	if err != nil {
+		followupEmbed(s, i, &discordgo.MessageEmbed{Title: "Error", Description: err.Error(), Color: 0xFF0000})
+		return
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("%s — %s", getTextWithCtx(i, "title_portfolio"), target.Username),
+		Color:  accentColor(i, 0x9B59B6),
+		Fields: []*discordgo.MessageEmbedField{{Name: getTextWithCtx(i, "embed_owner"), Value: wl.Address, Inline: false}},
+	}
+	if target.Avatar != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: target.AvatarURL("128")}
+	}
+	if len(assets) == 0 {
+		embed.Description = getTextWithCtx(i, "not_found")
+		followupEmbed(s, i, embed)
+		return
+	}
+	yes := map[bool]string{true: "✅", false: "❌"}
+	var lines []string
+	for k := range assets {
+		a := &assets[k]
+		title := a.Title
+		if title == "" {
+			title = a.IpId
+		}
+		line := fmt.Sprintf("**%s**\n`%s`", truncateRunes(title, 80), a.IpId)
+		if lt := storyclient.PrimaryLicense(a); lt != nil && lt.Terms != nil {
+			line += fmt.Sprintf(" · 💼 %s · 🧩 %s · 💰 %s", yes[lt.Terms.CommercialUse], yes[lt.Terms.DerivativesAllowed], formatRevSharePercent(lt.Terms.CommercialRevShare))
+		}
+		lines = append(lines, line)
+	}
+	embed.Description = truncateRunes(strings.Join(lines, "\n"), 4096)
+	_, _ = followupEmbedWithComponents(s, i, embed, nil)
+}